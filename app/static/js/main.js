@@ -2,6 +2,22 @@
 let currentRecipeData = null;
 let mdcComponents = {};
 
+// Fetch for endpoints that need a signed-in user: sends the session cookie and
+// redirects to the login page when the session is missing or expired.
+async function apiFetch(url, options = {}) {
+    const response = await fetch(url, { credentials: 'same-origin', ...options });
+    if (response.status === 401) {
+        window.location.href = '/login';
+        throw new Error('Please log in to continue');
+    }
+    return response;
+}
+
+async function logout() {
+    await fetch('/auth/logout', { method: 'POST', credentials: 'same-origin' });
+    window.location.href = '/login';
+}
+
 // Initialize Material Design Components
 function initializeMDCComponents() {
     // Initialize text fields
@@ -163,28 +179,49 @@ async function generateRecipe() {
     setFormEnabled(false);
     
     try {
-        const response = await fetch('/generate_recipe', {
+        const response = await apiFetch('/generate_recipe/stream', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
+                'Accept': 'text/event-stream',
             },
             body: JSON.stringify(formData)
         });
         
-        const data = await response.json();
-        
-        if (response.ok) {
-            currentRecipeData = data;
-            displayRecipe(data);
-            showElement('recipeResult');
-            // Hide edit button for new recipes (no ID yet)
-            hideElement('editRecipeBtn');
-            // Animate the recipe card
-            const recipeCard = document.getElementById('recipeResult');
-            recipeCard.style.animation = 'fadeIn 0.5s ease-out';
-        } else {
+        if (!response.ok) {
+            const data = await response.json();
             showError(data.error || 'Failed to generate recipe');
+            return;
         }
+        
+        const content = document.getElementById('recipeContent');
+        content.textContent = '';
+        document.getElementById('recipeMeta').innerHTML = '';
+        hideElement('editRecipeBtn');
+        
+        let streamStarted = false;
+        await readRecipeStream(response, {
+            delta(data) {
+                if (!streamStarted) {
+                    streamStarted = true;
+                    hideElement('loadingIndicator');
+                    showElement('recipeResult');
+                }
+                content.textContent += data.text;
+            },
+            done(data) {
+                currentRecipeData = data;
+                displayRecipe(data);
+                showElement('recipeResult');
+                // Animate the recipe card
+                const recipeCard = document.getElementById('recipeResult');
+                recipeCard.style.animation = 'fadeIn 0.5s ease-out';
+            },
+            error(data) {
+                hideElement('recipeResult');
+                showError(data.error || 'Failed to generate recipe');
+            }
+        });
     } catch (error) {
         showError('Network error. Please check your connection and try again.');
     } finally {
@@ -196,6 +233,41 @@ async function generateRecipe() {
     }
 }
 
+// Read a Server-Sent Events response body and dispatch each event to its handler
+async function readRecipeStream(response, handlers) {
+    const reader = response.body.getReader();
+    const decoder = new TextDecoder();
+    let buffer = '';
+    
+    while (true) {
+        const { value, done } = await reader.read();
+        if (done) {
+            break;
+        }
+        buffer += decoder.decode(value, { stream: true });
+        
+        let boundary;
+        while ((boundary = buffer.indexOf('\n\n')) !== -1) {
+            const rawEvent = buffer.slice(0, boundary);
+            buffer = buffer.slice(boundary + 2);
+            
+            let eventName = 'message';
+            const dataLines = [];
+            rawEvent.split('\n').forEach(line => {
+                if (line.startsWith('event:')) {
+                    eventName = line.slice(6).trim();
+                } else if (line.startsWith('data:')) {
+                    dataLines.push(line.slice(5));
+                }
+            });
+            
+            if (handlers[eventName] && dataLines.length > 0) {
+                handlers[eventName](JSON.parse(dataLines.join('\n')));
+            }
+        }
+    }
+}
+
 // Display recipe
 function displayRecipe(data) {
     const content = document.getElementById('recipeContent');
@@ -222,7 +294,7 @@ async function saveRecipe() {
     
     try {
         console.log('Saving recipe with data:', currentRecipeData);
-        const response = await fetch('/save_recipe', {
+        const response = await apiFetch('/save_recipe', {
             method: 'POST',
             headers: {
                 'Content-Type': 'application/json',
@@ -336,7 +408,7 @@ async function loadSavedRecipes() {
     container.innerHTML = '<div class="loading-message">Loading saved recipes...</div>';
     
     try {
-        const response = await fetch('/api/recipes?per_page=50');
+        const response = await apiFetch('/api/recipes?per_page=50');
         const data = await response.json();
         
         if (response.ok) {
@@ -376,7 +448,7 @@ async function searchSavedRecipes(searchTerm, ratingFilter = '') {
             url += '&' + params.toString();
         }
         
-        const response = await fetch(url);
+        const response = await apiFetch(url);
         const data = await response.json();
         
         if (response.ok) {
@@ -463,7 +535,7 @@ function displaySavedRecipes(recipes, searchTerm = '') {
 // View a saved recipe
 async function viewSavedRecipe(recipeId) {
     try {
-        const response = await fetch(`/api/recipes/${recipeId}`);
+        const response = await apiFetch(`/api/recipes/${recipeId}`);
         const recipe = await response.json();
         
         if (response.ok) {
@@ -510,7 +582,7 @@ async function deleteSavedRecipe(recipeId) {
     }
     
     try {
-        const response = await fetch(`/api/recipes/${recipeId}`, {
+        const response = await apiFetch(`/api/recipes/${recipeId}`, {
             method: 'DELETE'
         });
         
@@ -602,7 +674,7 @@ async function saveEditedRecipe() {
     };
     
     try {
-        const response = await fetch(`/api/recipes/${currentRecipeData.id}`, {
+        const response = await apiFetch(`/api/recipes/${currentRecipeData.id}`, {
             method: 'PUT',
             headers: {
                 'Content-Type': 'application/json',
@@ -756,7 +828,7 @@ function updateStarDisplay(rating, isHover = false) {
 // Update recipe rating via API
 async function updateRecipeRating(recipeId, rating) {
     try {
-        const response = await fetch(`/api/recipes/${recipeId}/rating`, {
+        const response = await apiFetch(`/api/recipes/${recipeId}/rating`, {
             method: 'PUT',
             headers: {
                 'Content-Type': 'application/json',
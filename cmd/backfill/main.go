@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"recipe-ai/internal/config"
+	"recipe-ai/internal/database"
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/recipeparse"
+
+	"gorm.io/gorm"
+)
+
+func main() {
+	var force = flag.Bool("force", false, "Also re-parse recipes that already have ingredient rows, replacing them with heuristic parses of the text even when they were saved from the model's structured output")
+	var dryRun = flag.Bool("dry-run", false, "Parse recipes and report counts without writing")
+	var batchSize = flag.Int("batch-size", 100, "Number of recipes to process per batch")
+	flag.Parse()
+
+	cfg := config.Load()
+
+	db, err := database.Initialize(cfg.DatabaseURL, "production")
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	query := db.Model(&models.Recipe{})
+	if *force && !*dryRun {
+		log.Print("-force: replacing the ingredient and step rows of every recipe with ones parsed from its text, including rows saved from structured output")
+	}
+	if !*force {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM recipe_ingredients ri WHERE ri.recipe_id = recipes.id)")
+	}
+
+	var processed, skipped int
+	var recipes []models.Recipe
+	result := query.FindInBatches(&recipes, *batchSize, func(tx *gorm.DB, batch int) error {
+		for _, recipe := range recipes {
+			ingredients, steps := recipeparse.ParseContent(recipe.RecipeContent)
+			if len(ingredients) == 0 && len(steps) == 0 {
+				log.Printf("Recipe %d (%q): no ingredients or steps found, skipping", recipe.ID, recipe.Title)
+				skipped++
+				continue
+			}
+
+			if *dryRun {
+				log.Printf("Recipe %d (%q): %d ingredients, %d steps", recipe.ID, recipe.Title, len(ingredients), len(steps))
+				processed++
+				continue
+			}
+
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return models.ReplaceRecipeStructure(tx, recipe.ID, ingredients, steps)
+			})
+			if err != nil {
+				return err
+			}
+			processed++
+		}
+		return nil
+	})
+	if result.Error != nil {
+		log.Fatal("Backfill failed:", result.Error)
+	}
+
+	log.Printf("Backfill completed: %d recipes processed, %d skipped", processed, skipped)
+}
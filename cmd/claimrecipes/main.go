@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"recipe-ai/internal/config"
+	"recipe-ai/internal/database"
+	"recipe-ai/internal/models"
+)
+
+// claimrecipes gives recipes saved before accounts existed, which have no
+// owner and are hidden from the API, to an existing account.
+func main() {
+	var email = flag.String("email", "", "Email of the account to give the recipes to")
+	var dryRun = flag.Bool("dry-run", false, "Count the recipes without an owner and exit")
+	flag.Parse()
+
+	cfg := config.Load()
+
+	db, err := database.Initialize(cfg.DatabaseURL, "production")
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	orphans := db.Unscoped().Model(&models.Recipe{}).Where("user_id IS NULL")
+	if *dryRun {
+		var count int64
+		if err := orphans.Count(&count).Error; err != nil {
+			log.Fatal("Failed to count recipes:", err)
+		}
+		log.Printf("%d recipes have no owner", count)
+		return
+	}
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	var user models.User
+	if err := db.Where("email = ?", strings.ToLower(strings.TrimSpace(*email))).First(&user).Error; err != nil {
+		log.Fatalf("No account found for %s: %v", *email, err)
+	}
+
+	result := orphans.Update("user_id", user.ID)
+	if result.Error != nil {
+		log.Fatal("Failed to assign recipes:", result.Error)
+	}
+
+	log.Printf("Assigned %d recipes to %s", result.RowsAffected, user.Email)
+}
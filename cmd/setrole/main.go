@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"recipe-ai/internal/auth"
+	"recipe-ai/internal/config"
+	"recipe-ai/internal/database"
+	"recipe-ai/internal/models"
+)
+
+// setrole changes the role of an existing account. It is how the first admin
+// is set up; after that admins manage roles through the API.
+func main() {
+	var email = flag.String("email", "", "Email of the account to change")
+	var roleName = flag.String("role", string(auth.RoleAdmin), "Role to give the account (admin/editor/viewer)")
+	flag.Parse()
+
+	if *email == "" {
+		log.Fatal("-email is required")
+	}
+	role, err := auth.ParseRole(*roleName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg := config.Load()
+
+	db, err := database.Initialize(cfg.DatabaseURL, "production")
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+
+	result := db.Model(&models.User{}).
+		Where("email = ?", strings.ToLower(strings.TrimSpace(*email))).
+		Update("role", string(role))
+	if result.Error != nil {
+		log.Fatal("Failed to update role:", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		log.Fatalf("No account found for %s; register or sign in first", *email)
+	}
+
+	log.Printf("%s is now %s", *email, role)
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoCredentials = errors.New("no credentials provided")
+	ErrInvalidToken  = errors.New("invalid, expired or revoked API token")
+)
+
+// lastUsedResolution limits last_used_at writes to one per token per minute.
+const lastUsedResolution = time.Minute
+
+// Identity is the authenticated caller of a request. Token is nil for browser
+// sessions, which are not limited by scopes.
+type Identity struct {
+	UserID uint
+	Role   Role
+	Token  *models.APIToken
+}
+
+func (i *Identity) Allows(scope string) bool {
+	return i.Token == nil || i.Token.HasScope(scope)
+}
+
+// Authenticator resolves a request to an Identity from either an
+// "Authorization: Bearer" API token or a session cookie.
+type Authenticator struct {
+	db       *gorm.DB
+	sessions *SessionManager
+}
+
+func NewAuthenticator(db *gorm.DB, sessions *SessionManager) *Authenticator {
+	return &Authenticator{db: db, sessions: sessions}
+}
+
+// Authenticate returns ErrNoCredentials when the request carries neither a
+// token nor a session, and ErrInvalidToken for unusable tokens. A request
+// with an Authorization header is never authenticated by its cookie instead.
+func (a *Authenticator) Authenticate(r *http.Request) (*Identity, error) {
+	if header := r.Header.Get("Authorization"); header != "" {
+		raw, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+		return a.authenticateToken(strings.TrimSpace(raw))
+	}
+
+	userID, ok := a.sessions.UserID(r)
+	if !ok {
+		return nil, ErrNoCredentials
+	}
+
+	role, err := a.role(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The account was deleted after the session was issued.
+		return nil, ErrNoCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{UserID: userID, Role: role}, nil
+}
+
+func (a *Authenticator) role(userID uint) (Role, error) {
+	var user models.User
+	if err := a.db.Select("role").First(&user, userID).Error; err != nil {
+		return "", err
+	}
+	return Role(user.Role), nil
+}
+
+func (a *Authenticator) authenticateToken(raw string) (*Identity, error) {
+	if !strings.HasPrefix(raw, tokenPrefix) {
+		return nil, ErrInvalidToken
+	}
+
+	var token models.APIToken
+	if err := a.db.Where("token_hash = ?", HashToken(raw)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if !token.Active(now) {
+		return nil, ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		err := a.db.Model(&models.APIToken{}).Where("id = ?", token.ID).Update("last_used_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+		token.LastUsedAt = &now
+	}
+
+	role, err := a.role(token.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{UserID: token.UserID, Role: role, Token: &token}, nil
+}
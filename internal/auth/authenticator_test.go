@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"recipe-ai/internal/models"
+)
+
+func TestIdentityAllows(t *testing.T) {
+	session := &Identity{UserID: 1, Role: RoleEditor}
+	token := &Identity{UserID: 1, Role: RoleEditor, Token: &models.APIToken{Scopes: "recipes:read,generate"}}
+
+	tests := []struct {
+		identity *Identity
+		scope    string
+		want     bool
+	}{
+		{session, ScopeRecipesWrite, true},
+		{token, ScopeRecipesRead, true},
+		{token, ScopeGenerate, true},
+		{token, ScopeRecipesWrite, false},
+		{token, "recipes", false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.identity.Allows(tt.scope); got != tt.want {
+			t.Errorf("Allows(%q) with token %v = %v, want %v", tt.scope, tt.identity.Token != nil, got, tt.want)
+		}
+	}
+}
+
+func TestAuthenticateRejectsMalformedTokens(t *testing.T) {
+	// Malformed headers are rejected before any database lookup.
+	a := NewAuthenticator(nil, NewSessionManager("secret", 0, false))
+
+	for _, header := range []string{"Basic dXNlcjpwYXNz", "Bearer", "Bearer not-a-token", "rcp_abc"} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Authorization", header)
+		if _, err := a.Authenticate(r); err != ErrInvalidToken {
+			t.Errorf("Authenticate(%q) error = %v, want ErrInvalidToken", header, err)
+		}
+	}
+
+	if _, err := a.Authenticate(httptest.NewRequest("GET", "/", nil)); err != ErrNoCredentials {
+		t.Errorf("Authenticate without credentials error = %v, want ErrNoCredentials", err)
+	}
+}
@@ -0,0 +1,321 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	oidcStateCookieName = "recipe_oidc"
+	oidcStateCookiePath = "/auth/oidc"
+	oidcStateTTL        = 10 * time.Minute
+)
+
+var ErrOIDCState = errors.New("sign-in attempt expired or did not start here, please try again")
+
+// OIDCClaims are the verified identity claims of an ID token.
+type OIDCClaims struct {
+	Issuer        string
+	Subject       string
+	Email         string
+	EmailVerified bool
+}
+
+// OIDCProvider signs users in with an OpenID Connect identity provider using
+// the authorization code flow with PKCE. The issuer's discovery document and
+// signing keys are fetched on first use and cached.
+type OIDCProvider struct {
+	client       *http.Client
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	key          []byte
+	secure       bool
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      *keySet
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// oidcState is kept in a short-lived signed cookie between the redirect to the
+// identity provider and its callback, so no server-side storage is needed.
+type oidcState struct {
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Expires  int64  `json:"exp"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func NewOIDCProvider(issuer, clientID, clientSecret, redirectURL, secretKey string, secure bool) *OIDCProvider {
+	return &OIDCProvider{
+		client:       &http.Client{Timeout: 10 * time.Second},
+		issuer:       strings.TrimRight(issuer, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		key:          []byte(secretKey),
+		secure:       secure,
+	}
+}
+
+// AuthCodeURL starts a sign-in: it stores a fresh state, nonce and PKCE
+// verifier in a cookie on w and returns the identity provider URL to send the
+// browser to.
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, w http.ResponseWriter) (string, error) {
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	state := oidcState{Expires: time.Now().Add(oidcStateTTL).Unix()}
+	for _, value := range []*string{&state.State, &state.Nonce, &state.Verifier} {
+		if *value, err = randomString(); err != nil {
+			return "", err
+		}
+	}
+	if err := p.setState(w, state); err != nil {
+		return "", err
+	}
+
+	challenge := sha256.Sum256([]byte(state.Verifier))
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {"openid email profile"},
+		"state":                 {state.State},
+		"nonce":                 {state.Nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange completes a sign-in from the identity provider's callback request:
+// it checks the state, redeems the code with the PKCE verifier and verifies
+// the returned ID token's signature, issuer, audience, expiry and nonce.
+func (p *OIDCProvider) Exchange(ctx context.Context, r *http.Request, w http.ResponseWriter) (*OIDCClaims, error) {
+	state, ok := p.state(r)
+	p.clearState(w)
+	if !ok || r.URL.Query().Get("state") != state.State {
+		return nil, ErrOIDCState
+	}
+
+	query := r.URL.Query()
+	if errCode := query.Get("error"); errCode != "" {
+		return nil, fmt.Errorf("identity provider returned %s: %s", errCode, query.Get("error_description"))
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, fmt.Errorf("identity provider returned no authorization code")
+	}
+
+	discovery, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rawIDToken, err := p.redeem(ctx, discovery.TokenEndpoint, code, state.Verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := p.verifyIDToken(ctx, discovery, rawIDToken)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if claims.Nonce != state.Nonce {
+		return nil, fmt.Errorf("invalid ID token: nonce mismatch")
+	}
+
+	return &OIDCClaims{
+		Issuer:        claims.Issuer,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: bool(claims.EmailVerified),
+	}, nil
+}
+
+func (p *OIDCProvider) redeem(ctx context.Context, tokenEndpoint, code, verifier string) (string, error) {
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientID},
+		"code_verifier": {verifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.clientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.clientID), url.QueryEscape(p.clientSecret))
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to redeem authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var tokenResp oidcTokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&tokenResp); err != nil {
+		return "", fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, tokenResp.Error, tokenResp.ErrorDescription)
+	}
+	if tokenResp.IDToken == "" {
+		return "", fmt.Errorf("token response has no id_token")
+	}
+
+	return tokenResp.IDToken, nil
+}
+
+// discover fetches and caches the issuer's discovery document. The fetch
+// happens outside the lock; concurrent first sign-ins may each fetch it, and
+// the first to finish is kept.
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	cached := p.discovery
+	p.mu.Unlock()
+
+	if cached != nil {
+		return cached, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(ctx, p.issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+
+	if strings.TrimRight(discovery.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("OIDC discovery failed: issuer %q does not match configured %q", discovery.Issuer, p.issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, fmt.Errorf("OIDC discovery failed: document is missing required endpoints")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.discovery == nil {
+		p.discovery = &discovery
+	}
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+func (p *OIDCProvider) setState(w http.ResponseWriter, state oidcState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(data)
+
+	// SameSite=Lax still sends the cookie on the identity provider's top-level
+	// redirect back to the callback.
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    payload + "." + p.sign(payload),
+		Path:     oidcStateCookiePath,
+		MaxAge:   int(oidcStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   p.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return nil
+}
+
+func (p *OIDCProvider) state(r *http.Request) (oidcState, bool) {
+	var state oidcState
+
+	cookie, err := r.Cookie(oidcStateCookieName)
+	if err != nil {
+		return state, false
+	}
+
+	payload, signature, ok := strings.Cut(cookie.Value, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(p.sign(payload))) {
+		return state, false
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || json.Unmarshal(data, &state) != nil {
+		return state, false
+	}
+
+	if state.State == "" || time.Now().Unix() >= state.Expires {
+		return state, false
+	}
+	return state, true
+}
+
+func (p *OIDCProvider) clearState(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookieName,
+		Value:    "",
+		Path:     oidcStateCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   p.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (p *OIDCProvider) sign(payload string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte("oidc-state:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
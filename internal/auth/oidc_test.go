@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testClientID = "recipe-ai"
+
+// testIssuer is a mock OpenID Connect issuer. Its token endpoint answers with
+// whatever ID token the test builds for the nonce of the sign-in.
+type testIssuer struct {
+	*httptest.Server
+	rsaKey  *rsa.PrivateKey
+	ecKey   *ecdsa.PrivateKey
+	idToken string
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &testIssuer{rsaKey: rsaKey, ecKey: ecKey}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.URL,
+			"authorization_endpoint": issuer.URL + "/authorize",
+			"token_endpoint":         issuer.URL + "/token",
+			"jwks_uri":               issuer.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		b64 := base64.RawURLEncoding.EncodeToString
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{
+			{
+				"kty": "RSA", "kid": "rsa", "use": "sig",
+				"n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC", "kid": "ec", "use": "sig", "crv": "P-256",
+				"x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32))),
+			},
+		}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "code" || r.FormValue("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"id_token": issuer.idToken})
+	})
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+	return issuer
+}
+
+// claims returns valid ID token claims for the given nonce.
+func (i *testIssuer) claims(nonce string) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"iss":            i.URL,
+		"sub":            "user-1",
+		"aud":            testClientID,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "Cook@Example.com",
+		"email_verified": true,
+	}
+}
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	signed := encodeSegment(t, map[string]string{"alg": "ES256", "kid": kid}) + "." + encodeSegment(t, claims)
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// signIn runs the authorization code flow against the issuer, with the ID
+// token for the sign-in's nonce built by idToken.
+func signIn(t *testing.T, issuer *testIssuer, idToken func(nonce string) string) (*OIDCClaims, error) {
+	t.Helper()
+	ctx := context.Background()
+	provider := NewOIDCProvider(issuer.URL, testClientID, "", "http://localhost/auth/oidc/callback", "secret", false)
+
+	start := httptest.NewRecorder()
+	authURL, err := provider.AuthCodeURL(ctx, start)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := parsed.Query()
+	issuer.idToken = idToken(query.Get("nonce"))
+
+	callback := httptest.NewRequest("GET", "/auth/oidc/callback?"+url.Values{
+		"state": {query.Get("state")},
+		"code":  {"code"},
+	}.Encode(), nil)
+	for _, cookie := range start.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	return provider.Exchange(ctx, callback, httptest.NewRecorder())
+}
+
+func TestOIDCSignIn(t *testing.T) {
+	issuer := newTestIssuer(t)
+
+	tests := []struct {
+		name    string
+		idToken func(nonce string) string
+	}{
+		{"RS256", func(nonce string) string {
+			return signRS256(t, issuer.rsaKey, "rsa", issuer.claims(nonce))
+		}},
+		{"ES256", func(nonce string) string {
+			return signES256(t, issuer.ecKey, "ec", issuer.claims(nonce))
+		}},
+		{"audience list", func(nonce string) string {
+			claims := issuer.claims(nonce)
+			claims["aud"] = []string{"other", testClientID}
+			claims["azp"] = testClientID
+			return signRS256(t, issuer.rsaKey, "rsa", claims)
+		}},
+		{"expired within clock skew", func(nonce string) string {
+			claims := issuer.claims(nonce)
+			claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+			return signRS256(t, issuer.rsaKey, "rsa", claims)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := signIn(t, issuer, tt.idToken)
+			if err != nil {
+				t.Fatalf("sign-in failed: %v", err)
+			}
+			want := OIDCClaims{Issuer: issuer.URL, Subject: "user-1", Email: "cook@example.com", EmailVerified: true}
+			if *claims != want {
+				t.Errorf("claims = %+v, want %+v", *claims, want)
+			}
+		})
+	}
+}
+
+func TestOIDCRejectsInvalidIDTokens(t *testing.T) {
+	issuer := newTestIssuer(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&issuer.rsaKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withClaim := func(name string, value interface{}) func(string) string {
+		return func(nonce string) string {
+			claims := issuer.claims(nonce)
+			claims[name] = value
+			return signRS256(t, issuer.rsaKey, "rsa", claims)
+		}
+	}
+
+	tests := []struct {
+		name    string
+		idToken func(nonce string) string
+		wantErr string
+	}{
+		{"bad signature", func(nonce string) string {
+			return signRS256(t, otherKey, "rsa", issuer.claims(nonce))
+		}, "signature verification failed"},
+		{"tampered claims", func(nonce string) string {
+			token := signRS256(t, issuer.rsaKey, "rsa", issuer.claims(nonce))
+			parts := strings.Split(token, ".")
+			claims := issuer.claims(nonce)
+			claims["sub"] = "admin"
+			return parts[0] + "." + encodeSegment(t, claims) + "." + parts[2]
+		}, "signature verification failed"},
+		{"alg none", func(nonce string) string {
+			return encodeSegment(t, map[string]string{"alg": "none", "kid": "rsa"}) + "." + encodeSegment(t, issuer.claims(nonce)) + "."
+		}, `unsupported signing algorithm "none"`},
+		{"HS256 with the public key as secret", func(nonce string) string {
+			signed := encodeSegment(t, map[string]string{"alg": "HS256", "kid": "rsa"}) + "." + encodeSegment(t, issuer.claims(nonce))
+			mac := hmac.New(sha256.New, publicKeyDER)
+			mac.Write([]byte(signed))
+			return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+		}, `unsupported signing algorithm "HS256"`},
+		{"RS256 header on the EC key", func(nonce string) string {
+			return signRS256(t, issuer.rsaKey, "ec", issuer.claims(nonce))
+		}, "signature verification failed"},
+		{"unknown key", func(nonce string) string {
+			return signRS256(t, issuer.rsaKey, "rotated", issuer.claims(nonce))
+		}, `unknown signing key "rotated"`},
+		{"wrong issuer", withClaim("iss", "https://evil.example.com"), "unexpected issuer"},
+		{"wrong audience", withClaim("aud", "other-client"), "not issued for this client"},
+		{"authorized for another client", func(nonce string) string {
+			claims := issuer.claims(nonce)
+			claims["aud"] = []string{testClientID, "other"}
+			claims["azp"] = "other"
+			return signRS256(t, issuer.rsaKey, "rsa", claims)
+		}, "authorized for another client"},
+		{"expired", withClaim("exp", time.Now().Add(-time.Hour).Unix()), "expired"},
+		{"no expiry", withClaim("exp", 0), "expired"},
+		{"issued in the future", withClaim("iat", time.Now().Add(time.Hour).Unix()), "issued in the future"},
+		{"no subject", withClaim("sub", ""), "no subject"},
+		{"nonce mismatch", withClaim("nonce", "replayed"), "nonce mismatch"},
+		{"malformed", func(string) string { return "not-a-jwt" }, "malformed token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := signIn(t, issuer, tt.idToken)
+			if err == nil {
+				t.Fatalf("sign-in succeeded with %+v, want error containing %q", *claims, tt.wantErr)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOIDCRejectsForgedState(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDCProvider(issuer.URL, testClientID, "", "http://localhost/auth/oidc/callback", "secret", false)
+
+	start := httptest.NewRecorder()
+	if _, err := provider.AuthCodeURL(context.Background(), start); err != nil {
+		t.Fatal(err)
+	}
+
+	callback := httptest.NewRequest("GET", "/auth/oidc/callback?state=forged&code=code", nil)
+	for _, cookie := range start.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+	if _, err := provider.Exchange(context.Background(), callback, httptest.NewRecorder()); err != ErrOIDCState {
+		t.Errorf("error = %v, want ErrOIDCState", err)
+	}
+}
+
+func TestOIDCFetchesKeysOutsideLock(t *testing.T) {
+	issuer := newTestIssuer(t)
+	provider := NewOIDCProvider(issuer.URL, testClientID, "", "http://localhost/auth/oidc/callback", "secret", false)
+	if _, err := provider.AuthCodeURL(context.Background(), httptest.NewRecorder()); err != nil {
+		t.Fatal(err)
+	}
+
+	entered, release := make(chan struct{}), make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+		w.Write([]byte(`{"keys":[]}`))
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	go provider.signingKey(context.Background(), slow.URL, "rsa")
+	<-entered
+
+	// A sign-in must not wait for another request's slow JWKS fetch.
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := provider.AuthCodeURL(ctx, httptest.NewRecorder())
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("AuthCodeURL: %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("AuthCodeURL blocked behind a JWKS fetch")
+	}
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash == "correct horse" {
+		t.Fatal("HashPassword returned the password itself")
+	}
+	if !CheckPassword(hash, "correct horse") {
+		t.Error("CheckPassword rejected the right password")
+	}
+	if CheckPassword(hash, "correct horsE") {
+		t.Error("CheckPassword accepted a wrong password")
+	}
+
+	other, err := HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash {
+		t.Error("two hashes of the same password are equal; they should be salted")
+	}
+}
+
+func TestHashPasswordLength(t *testing.T) {
+	tests := []struct {
+		password string
+		wantErr  bool
+	}{
+		{strings.Repeat("a", MinPasswordLength-1), true},
+		{strings.Repeat("a", MinPasswordLength), false},
+		{strings.Repeat("a", MaxPasswordLength), false},
+		{strings.Repeat("a", MaxPasswordLength+1), true},
+	}
+
+	for _, tt := range tests {
+		_, err := HashPassword(tt.password)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("HashPassword(%d characters) error = %v, want error %v", len(tt.password), err, tt.wantErr)
+		}
+		if tt.wantErr && err != ErrPasswordLength {
+			t.Errorf("HashPassword(%d characters) error = %v, want ErrPasswordLength", len(tt.password), err)
+		}
+	}
+}
+
+func TestCheckPasswordEmptyHash(t *testing.T) {
+	for _, password := range []string{"", "not-a-real-password"} {
+		if CheckPassword("", password) {
+			t.Errorf("CheckPassword(\"\", %q) = true, want false", password)
+		}
+	}
+}
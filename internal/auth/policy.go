@@ -0,0 +1,63 @@
+package auth
+
+import "fmt"
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleEditor Role = "editor"
+	RoleViewer Role = "viewer"
+
+	// DefaultRole is given to new accounts.
+	DefaultRole = RoleEditor
+)
+
+var Roles = []Role{RoleAdmin, RoleEditor, RoleViewer}
+
+// Permission is what a route requires of its caller. Permissions that share
+// their name with an API token scope also require that scope from tokens;
+// the others cannot be granted to tokens at all.
+type Permission string
+
+const (
+	PermRecipesRead  Permission = ScopeRecipesRead
+	PermRecipesWrite Permission = ScopeRecipesWrite
+	PermGenerate     Permission = ScopeGenerate
+
+	PermAdminRecipes Permission = "admin:recipes"
+	PermAdminUsers   Permission = "admin:users"
+	PermAdminUsage   Permission = "admin:usage"
+)
+
+// Policy lists the roles granted each permission. Routes declare the
+// permission they need with middleware.RequirePermission; a new permission
+// must be added here before any role can use it.
+var Policy = map[Permission][]Role{
+	PermRecipesRead:  {RoleViewer, RoleEditor, RoleAdmin},
+	PermRecipesWrite: {RoleEditor, RoleAdmin},
+	PermGenerate:     {RoleEditor, RoleAdmin},
+
+	PermAdminRecipes: {RoleAdmin},
+	PermAdminUsers:   {RoleAdmin},
+	PermAdminUsage:   {RoleAdmin},
+}
+
+// Can reports whether the policy grants the role a permission.
+func (r Role) Can(perm Permission) bool {
+	for _, role := range Policy[perm] {
+		if role == r {
+			return true
+		}
+	}
+	return false
+}
+
+func ParseRole(value string) (Role, error) {
+	for _, role := range Roles {
+		if string(role) == value {
+			return role, nil
+		}
+	}
+	return "", fmt.Errorf("unknown role %q; valid roles are admin, editor and viewer", value)
+}
@@ -0,0 +1,57 @@
+package auth
+
+import "testing"
+
+func TestPolicy(t *testing.T) {
+	tests := []struct {
+		perm                  Permission
+		admin, editor, viewer bool
+	}{
+		{PermRecipesRead, true, true, true},
+		{PermRecipesWrite, true, true, false},
+		{PermGenerate, true, true, false},
+		{PermAdminRecipes, true, false, false},
+		{PermAdminUsers, true, false, false},
+		{PermAdminUsage, true, false, false},
+		{Permission("recipes:delete"), false, false, false},
+	}
+
+	for _, tt := range tests {
+		for role, want := range map[Role]bool{RoleAdmin: tt.admin, RoleEditor: tt.editor, RoleViewer: tt.viewer} {
+			if got := role.Can(tt.perm); got != want {
+				t.Errorf("%s.Can(%s) = %v, want %v", role, tt.perm, got, want)
+			}
+		}
+		if Role("").Can(tt.perm) {
+			t.Errorf("the empty role can %s", tt.perm)
+		}
+	}
+}
+
+func TestPolicyCoversEveryPermission(t *testing.T) {
+	// A permission missing from Policy would silently be denied to everyone,
+	// admins included.
+	for perm := range Policy {
+		if !RoleAdmin.Can(perm) {
+			t.Errorf("admin cannot %s", perm)
+		}
+	}
+	for _, perm := range []Permission{PermRecipesRead, PermRecipesWrite, PermGenerate, PermAdminRecipes, PermAdminUsers, PermAdminUsage} {
+		if _, ok := Policy[perm]; !ok {
+			t.Errorf("%s is missing from Policy", perm)
+		}
+	}
+}
+
+func TestParseRole(t *testing.T) {
+	for _, role := range Roles {
+		if got, err := ParseRole(string(role)); err != nil || got != role {
+			t.Errorf("ParseRole(%q) = %q, %v, want %q", role, got, err, role)
+		}
+	}
+	for _, value := range []string{"", "Admin", "owner", " editor"} {
+		if got, err := ParseRole(value); err == nil {
+			t.Errorf("ParseRole(%q) = %q, want an error", value, got)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sessionRequest returns a request carrying the session cookie set on w.
+func sessionRequest(t *testing.T, w *httptest.ResponseRecorder) *http.Request {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/", nil)
+	for _, cookie := range w.Result().Cookies() {
+		r.AddCookie(cookie)
+	}
+	return r
+}
+
+func withSessionValue(value string) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.AddCookie(&http.Cookie{Name: SessionCookieName, Value: value})
+	return r
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	m := NewSessionManager("secret", time.Hour, true)
+	w := httptest.NewRecorder()
+	m.Issue(w, 42)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("got %d cookies, want 1", len(cookies))
+	}
+	cookie := cookies[0]
+	if cookie.Name != SessionCookieName || !cookie.HttpOnly || !cookie.Secure || cookie.SameSite != http.SameSiteLaxMode {
+		t.Errorf("cookie = %+v, want an HttpOnly, Secure, SameSite=Lax %s cookie", cookie, SessionCookieName)
+	}
+
+	userID, ok := m.UserID(sessionRequest(t, w))
+	if !ok || userID != 42 {
+		t.Errorf("UserID = %d, %v, want 42, true", userID, ok)
+	}
+}
+
+func TestSessionRejectsTampering(t *testing.T) {
+	m := NewSessionManager("secret", time.Hour, false)
+	w := httptest.NewRecorder()
+	m.Issue(w, 42)
+	value := w.Result().Cookies()[0].Value
+	parts := strings.Split(value, ".")
+
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"other user", "1." + parts[1] + "." + parts[2]},
+		{"extended expiry", parts[0] + ".9999999999." + parts[2]},
+		{"bad signature", parts[0] + "." + parts[1] + ".AAAA"},
+		{"no signature", parts[0] + "." + parts[1]},
+		{"extra part", value + ".x"},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if userID, ok := m.UserID(withSessionValue(tt.value)); ok {
+				t.Errorf("UserID(%q) = %d, true, want rejection", tt.value, userID)
+			}
+		})
+	}
+
+	other := NewSessionManager("other-secret", time.Hour, false)
+	if userID, ok := other.UserID(withSessionValue(value)); ok {
+		t.Errorf("cookie signed with another key accepted for user %d", userID)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	m := NewSessionManager("secret", -time.Minute, false)
+	w := httptest.NewRecorder()
+	m.Issue(w, 42)
+
+	if userID, ok := m.UserID(sessionRequest(t, w)); ok {
+		t.Errorf("expired session accepted for user %d", userID)
+	}
+}
+
+func TestSessionClear(t *testing.T) {
+	m := NewSessionManager("secret", time.Hour, false)
+	w := httptest.NewRecorder()
+	m.Clear(w)
+
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != SessionCookieName || cookies[0].MaxAge >= 0 || cookies[0].Value != "" {
+		t.Errorf("Clear set %+v, want an expired, empty %s cookie", cookies, SessionCookieName)
+	}
+}
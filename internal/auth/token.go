@@ -0,0 +1,86 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	ScopeRecipesRead  = "recipes:read"
+	ScopeRecipesWrite = "recipes:write"
+	ScopeGenerate     = "generate"
+
+	tokenPrefix = "rcp_"
+)
+
+var Scopes = []string{ScopeRecipesRead, ScopeRecipesWrite, ScopeGenerate}
+
+// NewToken returns a random personal access token, the short prefix shown in
+// token listings and the hash that is stored in its place.
+func NewToken() (token, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+
+	token = tokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, token[:len(tokenPrefix)+6], HashToken(token), nil
+}
+
+// NewShareToken returns a random, unguessable token for a public share link.
+func NewShareToken() (string, error) {
+	return randomString()
+}
+
+// HashToken hashes a token for storage and lookup. Tokens carry 256 bits of
+// randomness, so a fast unsalted hash is enough here.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ValidateScopes checks requested scopes against Scopes and returns them
+// deduplicated in canonical order.
+func ValidateScopes(requested []string) ([]string, error) {
+	wanted := make(map[string]bool, len(requested))
+	for _, scope := range requested {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if !isScope(scope) {
+			return nil, fmt.Errorf("unknown scope %q; valid scopes are %s", scope, strings.Join(Scopes, ", "))
+		}
+		wanted[scope] = true
+	}
+
+	if len(wanted) == 0 {
+		return nil, fmt.Errorf("at least one scope is required; valid scopes are %s", strings.Join(Scopes, ", "))
+	}
+
+	var scopes []string
+	for _, scope := range Scopes {
+		if wanted[scope] {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+func isScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func randomString() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
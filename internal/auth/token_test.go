@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewToken(t *testing.T) {
+	token, prefix, hash, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(token, tokenPrefix) {
+		t.Errorf("token %q does not start with %q", token, tokenPrefix)
+	}
+	if !strings.HasPrefix(token, prefix) || len(prefix) >= len(token) {
+		t.Errorf("prefix %q is not a proper prefix of the token", prefix)
+	}
+	if hash != HashToken(token) || len(hash) != 64 || strings.Contains(hash, token) {
+		t.Errorf("hash = %q, want HashToken(token)", hash)
+	}
+
+	other, _, _, err := NewToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == token {
+		t.Error("NewToken returned the same token twice")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	if HashToken("rcp_a") != HashToken("rcp_a") {
+		t.Error("HashToken is not deterministic")
+	}
+	if HashToken("rcp_a") == HashToken("rcp_b") {
+		t.Error("HashToken maps different tokens to the same hash")
+	}
+}
+
+func TestValidateScopes(t *testing.T) {
+	tests := []struct {
+		requested []string
+		want      []string
+	}{
+		{[]string{"recipes:read"}, []string{ScopeRecipesRead}},
+		{[]string{"generate", "recipes:read"}, []string{ScopeRecipesRead, ScopeGenerate}},
+		{[]string{" Recipes:Write ", "recipes:write"}, []string{ScopeRecipesWrite}},
+		{Scopes, Scopes},
+	}
+
+	for _, tt := range tests {
+		got, err := ValidateScopes(tt.requested)
+		if err != nil {
+			t.Errorf("ValidateScopes(%q) error: %v", tt.requested, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ValidateScopes(%q) = %q, want %q", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestValidateScopesInvalid(t *testing.T) {
+	for _, requested := range [][]string{nil, {}, {"admin:users"}, {"recipes:read", "recipes:*"}, {""}} {
+		if got, err := ValidateScopes(requested); err == nil {
+			t.Errorf("ValidateScopes(%q) = %q, want an error", requested, got)
+		}
+	}
+}
+
+func TestNewShareToken(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		token, err := NewShareToken()
+		if err != nil {
+			t.Fatal(err)
+		}
+		// 32 random bytes, URL-safe so the token can sit in /r/:token as is.
+		if len(token) != 43 || strings.Trim(token, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_") != "" {
+			t.Fatalf("share token %q is not 43 URL-safe characters", token)
+		}
+		if seen[token] {
+			t.Fatalf("NewShareToken returned %q twice", token)
+		}
+		seen[token] = true
+	}
+}
@@ -29,7 +29,7 @@ func Initialize(databaseURL string, environment string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	if err := db.AutoMigrate(&models.Recipe{}); err != nil {
+	if err := db.AutoMigrate(&models.User{}, &models.Recipe{}, &models.RecipeIngredient{}, &models.RecipeStep{}, &models.APIToken{}, &models.RecipeShare{}, &models.RecipeRevision{}, &models.Collection{}, &models.CollectionRecipe{}, &models.Tag{}, &models.MealPlanEntry{}, &models.ShoppingList{}, &models.ShoppingListItem{}, &models.PantryItem{}, &models.Conversation{}, &models.ConversationMessage{}); err != nil {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
@@ -0,0 +1,40 @@
+package database
+
+import (
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const trashPurgeInterval = time.Hour
+
+// StartTrashPurge permanently deletes recipes that have been in the trash for
+// longer than retention, once at startup and then every hour. A retention of
+// zero keeps trashed recipes until they are purged by hand.
+func StartTrashPurge(db *gorm.DB, retention time.Duration) {
+	if retention <= 0 {
+		logrus.Info("Automatic trash purge disabled")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(trashPurgeInterval)
+		defer ticker.Stop()
+
+		for {
+			purged, err := models.PurgeTrashedRecipes(db, time.Now().Add(-retention))
+			if err != nil {
+				logrus.WithError(err).Error("Failed to purge trashed recipes")
+			} else if purged > 0 {
+				logrus.WithFields(logrus.Fields{
+					"purged":    purged,
+					"retention": retention.String(),
+				}).Info("Purged trashed recipes")
+			}
+			<-ticker.C
+		}
+	}()
+}
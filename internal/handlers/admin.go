@@ -0,0 +1,229 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"recipe-ai/internal/auth"
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type UserUsage struct {
+	models.User
+	Recipes      int64      `json:"recipes"`
+	ActiveTokens int64      `json:"active_tokens"`
+	LastRecipeAt *time.Time `json:"last_recipe_at"`
+}
+
+// AdminGetRecipes lists recipes of every user with the same search, filter,
+// sort and pagination options as GetRecipes, plus an optional user_id filter.
+func (h *Handler) AdminGetRecipes(c *gin.Context) {
+	var ownerID uint64
+	if raw := c.Query("user_id"); raw != "" {
+		var err error
+		if ownerID, err = strconv.ParseUint(raw, 10, 32); err != nil || ownerID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "user_id must be a positive integer"})
+			return
+		}
+	}
+
+	h.listRecipes(c, func() *gorm.DB {
+		query := h.db.Model(&models.Recipe{})
+		if ownerID != 0 {
+			query = query.Where("recipes.user_id = ?", ownerID)
+		}
+		return query
+	})
+}
+
+// AdminDeleteRecipe permanently deletes any user's recipe, including one in
+// the trash.
+func (h *Handler) AdminDeleteRecipe(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	result := h.db.Unscoped().Delete(&models.Recipe{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recipe"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"recipe_id": id.(uint),
+		"admin_id":  c.GetUint("user_id"),
+	}).Info("Recipe deleted by admin")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recipe deleted successfully"})
+}
+
+func (h *Handler) AdminGetUsers(c *gin.Context) {
+	users, err := h.userUsage(0)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch users")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+func (h *Handler) AdminUpdateUserRole(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	role, err := auth.ParseRole(req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Demoting yourself could leave nobody able to manage roles.
+	if id.(uint) == c.GetUint("user_id") && role != auth.RoleAdmin {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot remove your own admin role"})
+		return
+	}
+
+	var user models.User
+	if err := h.db.First(&user, id.(uint)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch user")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	if err := h.db.Model(&user).Update("role", string(role)).Error; err != nil {
+		logrus.WithError(err).Error("Failed to update user role")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user role"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id":  user.ID,
+		"role":     role,
+		"admin_id": c.GetUint("user_id"),
+	}).Info("User role updated")
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// AdminGetUsage reports totals across all users and the most active users.
+func (h *Handler) AdminGetUsage(c *gin.Context) {
+	now := time.Now()
+
+	var roles []struct {
+		Role  string
+		Count int64
+	}
+	if err := h.db.Model(&models.User{}).Select("role, COUNT(*) AS count").Group("role").Scan(&roles).Error; err != nil {
+		logrus.WithError(err).Error("Failed to count users")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	var recipes struct {
+		Total      int64
+		LastWeek   int64
+		LastMonth  int64
+		Unassigned int64
+	}
+	err := h.db.Model(&models.Recipe{}).Select(`COUNT(*) AS total,
+		COUNT(*) FILTER (WHERE created_at >= ?) AS last_week,
+		COUNT(*) FILTER (WHERE created_at >= ?) AS last_month,
+		COUNT(*) FILTER (WHERE user_id IS NULL) AS unassigned`, now.AddDate(0, 0, -7), now.AddDate(0, -1, 0)).
+		Scan(&recipes).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count recipes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	var activeTokens int64
+	err = h.db.Model(&models.APIToken{}).
+		Where("revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", now).
+		Count(&activeTokens).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count API tokens")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	topUsers, err := h.userUsage(10)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch user usage")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch usage"})
+		return
+	}
+
+	byRole := gin.H{}
+	var totalUsers int64
+	for _, role := range roles {
+		byRole[role.Role] = role.Count
+		totalUsers += role.Count
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": gin.H{
+			"total":   totalUsers,
+			"by_role": byRole,
+		},
+		"recipes": gin.H{
+			"total":        recipes.Total,
+			"last_7_days":  recipes.LastWeek,
+			"last_30_days": recipes.LastMonth,
+			"unassigned":   recipes.Unassigned,
+		},
+		"api_tokens": gin.H{
+			"active": activeTokens,
+		},
+		"top_users": topUsers,
+	})
+}
+
+// userUsage lists users with their recipe and active token counts, most
+// recipes first. A limit of 0 returns every user.
+func (h *Handler) userUsage(limit int) ([]UserUsage, error) {
+	query := h.db.Model(&models.User{}).
+		Select(`users.*,
+			(SELECT COUNT(*) FROM recipes WHERE recipes.user_id = users.id AND recipes.deleted_at IS NULL) AS recipes,
+			(SELECT MAX(created_at) FROM recipes WHERE recipes.user_id = users.id AND recipes.deleted_at IS NULL) AS last_recipe_at,
+			(SELECT COUNT(*) FROM api_tokens WHERE api_tokens.user_id = users.id
+				AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)) AS active_tokens`, time.Now()).
+		Order("recipes DESC, users.id")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	users := []UserUsage{}
+	if err := query.Scan(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
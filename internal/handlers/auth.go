@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"recipe-ai/internal/auth"
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type CredentialsRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+func (h *Handler) LoginPage(c *gin.Context) {
+	c.HTML(http.StatusOK, "login.html", gin.H{"OIDC": h.oidc != nil})
+}
+
+func (h *Handler) Register(c *gin.Context) {
+	var req CredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	email, ok := normalizeEmail(req.Email)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide a valid email address"})
+		return
+	}
+
+	hash, err := auth.HashPassword(req.Password)
+	if err != nil {
+		if errors.Is(err, auth.ErrPasswordLength) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			logrus.WithError(err).Error("Failed to hash password")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		}
+		return
+	}
+
+	var existing int64
+	if err := h.db.Model(&models.User{}).Where("email = ?", email).Count(&existing).Error; err != nil {
+		logrus.WithError(err).Error("Failed to check for existing account")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+	if existing > 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "An account with this email already exists"})
+		return
+	}
+
+	user := models.User{Email: email, PasswordHash: hash, Role: string(auth.DefaultRole)}
+	if err := h.db.Create(&user).Error; err != nil {
+		logrus.WithError(err).Error("Failed to create account")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create account"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"ip":      c.ClientIP(),
+	}).Info("Account created")
+
+	h.sessions.Issue(c.Writer, user.ID)
+	c.JSON(http.StatusCreated, gin.H{"user": user})
+}
+
+func (h *Handler) Login(c *gin.Context) {
+	var req CredentialsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	email, _ := normalizeEmail(req.Email)
+
+	var user models.User
+	err := h.db.Where("email = ?", email).First(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithError(err).Error("Failed to fetch account for login")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to log in"})
+		return
+	}
+
+	// user.PasswordHash is empty for unknown emails, which CheckPassword
+	// still spends a bcrypt comparison on.
+	if !auth.CheckPassword(user.PasswordHash, req.Password) {
+		logrus.WithField("ip", c.ClientIP()).Warn("Failed login attempt")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid email or password"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"ip":      c.ClientIP(),
+	}).Info("User logged in")
+
+	h.sessions.Issue(c.Writer, user.ID)
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+func (h *Handler) Logout(c *gin.Context) {
+	h.sessions.Clear(c.Writer)
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out successfully"})
+}
+
+func (h *Handler) CurrentUser(c *gin.Context) {
+	var user models.User
+	if err := h.db.First(&user, c.GetUint("user_id")).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			h.sessions.Clear(c.Writer)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch current user")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"user": user})
+}
+
+// ownedRecipes starts a recipe query scoped to the signed-in user. Recipes of
+// other users are reported as not found rather than forbidden.
+func (h *Handler) ownedRecipes(c *gin.Context) *gorm.DB {
+	return h.db.Model(&models.Recipe{}).Where("recipes.user_id = ?", c.GetUint("user_id"))
+}
+
+func normalizeEmail(raw string) (string, bool) {
+	email := strings.ToLower(strings.TrimSpace(raw))
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || len(email) > 255 {
+		return "", false
+	}
+	return email, true
+}
@@ -0,0 +1,546 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxCollectionName        = 100
+	maxCollectionDescription = 2000
+)
+
+// CollectionRequest creates or updates a collection. On update, omitted fields
+// are left unchanged and a cover_recipe_id of 0 removes the cover.
+type CollectionRequest struct {
+	Name          *string `json:"name"`
+	Description   *string `json:"description"`
+	CoverRecipeID *uint   `json:"cover_recipe_id"`
+}
+
+type CollectionSummary struct {
+	models.Collection
+	RecipeCount int64 `json:"recipe_count" gorm:"->;column:recipe_count"`
+}
+
+// CollectionItem is a recipe as listed in a collection, in collection order.
+type CollectionItem struct {
+	models.Recipe
+	Position int       `json:"position" gorm:"->;column:position"`
+	AddedAt  time.Time `json:"added_at" gorm:"->;column:added_at"`
+}
+
+func (h *Handler) ListCollections(c *gin.Context) {
+	collections := []CollectionSummary{}
+	err := h.db.Model(&models.Collection{}).
+		Select(`collections.*, (SELECT COUNT(*) FROM collection_recipes
+			JOIN recipes ON recipes.id = collection_recipes.recipe_id AND recipes.deleted_at IS NULL
+			WHERE collection_recipes.collection_id = collections.id) AS recipe_count`).
+		Where("collections.user_id = ?", c.GetUint("user_id")).
+		Order("collections.name").
+		Scan(&collections).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch collections")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"collections": collections})
+}
+
+func (h *Handler) CreateCollection(c *gin.Context) {
+	var req CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	if req.Name == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Collection name is required"})
+		return
+	}
+
+	collection := models.Collection{UserID: c.GetUint("user_id")}
+	if !h.applyCollectionRequest(c, &collection, req) {
+		return
+	}
+
+	if err := h.db.Create(&collection).Error; err != nil {
+		logrus.WithError(err).Error("Failed to create collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create collection"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"collection_id": collection.ID,
+		"user_id":       collection.UserID,
+	}).Info("Collection created")
+
+	c.JSON(http.StatusCreated, gin.H{"collection": collection})
+}
+
+// GetCollection returns a collection with its recipes in collection order.
+func (h *Handler) GetCollection(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	items := []CollectionItem{}
+	err := collectionRecipes(h.db, collection.ID).
+		Select("recipes.*, collection_recipes.position, collection_recipes.created_at AS added_at").
+		Find(&items).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch collection recipes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"collection": collection,
+		"recipes":    items,
+	})
+}
+
+func (h *Handler) UpdateCollection(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req CollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	if !h.applyCollectionRequest(c, collection, req) {
+		return
+	}
+
+	err := h.db.Model(collection).Select("name", "description", "cover_recipe_id", "updated_at").Updates(collection).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update collection"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"collection_id": collection.ID,
+		"user_id":       collection.UserID,
+	}).Info("Collection updated")
+
+	c.JSON(http.StatusOK, gin.H{"collection": collection})
+}
+
+// DeleteCollection deletes a collection. Its recipes are not affected.
+func (h *Handler) DeleteCollection(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return
+	}
+
+	result := h.db.Where("user_id = ?", c.GetUint("user_id")).Delete(&models.Collection{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete collection"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"collection_id": id.(uint),
+		"user_id":       c.GetUint("user_id"),
+	}).Info("Collection deleted")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection deleted successfully"})
+}
+
+// AddCollectionRecipe adds a recipe to a collection, at the end unless a
+// position is given. Adding a recipe that is already there moves it.
+func (h *Handler) AddCollectionRecipe(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		RecipeID uint `json:"recipe_id"`
+		Position *int `json:"position"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.RecipeID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recipe_id is required"})
+		return
+	}
+	if req.Position != nil && *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position cannot be negative"})
+		return
+	}
+	if !h.checkOwnedRecipe(c, req.RecipeID) {
+		return
+	}
+
+	var position int
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		order, err := collectionOrder(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+
+		order = removeID(order, req.RecipeID)
+		position = len(order)
+		if req.Position != nil && *req.Position < position {
+			position = *req.Position
+		}
+		order = append(order[:position], append([]uint{req.RecipeID}, order[position:]...)...)
+
+		member := models.CollectionRecipe{CollectionID: collection.ID, RecipeID: req.RecipeID, Position: position}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&member).Error; err != nil {
+			return err
+		}
+		return saveCollectionOrder(tx, collection.ID, order)
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to add recipe to collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add recipe to collection"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"collection_id": collection.ID,
+		"recipe_id":     req.RecipeID,
+		"position":      position,
+	}).Info("Recipe added to collection")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Recipe added to collection",
+		"position": position,
+	})
+}
+
+func (h *Handler) RemoveCollectionRecipe(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	recipeID, err := strconv.ParseUint(c.Param("recipe_id"), 10, 32)
+	if err != nil || recipeID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var removed int64
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("collection_id = ? AND recipe_id = ?", collection.ID, recipeID).Delete(&models.CollectionRecipe{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if removed = result.RowsAffected; removed == 0 {
+			return nil
+		}
+
+		order, err := collectionOrder(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+		return saveCollectionOrder(tx, collection.ID, order)
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to remove recipe from collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove recipe from collection"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe is not in this collection"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recipe removed from collection"})
+}
+
+// ReorderCollection sets the order of a collection's recipes. recipe_ids must
+// list every recipe in the collection exactly once; trashed recipes keep their
+// place after them.
+func (h *Handler) ReorderCollection(c *gin.Context) {
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		RecipeIDs []uint `json:"recipe_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	var mismatch bool
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var visible []uint
+		if err := collectionRecipes(tx, collection.ID).Pluck("recipes.id", &visible).Error; err != nil {
+			return err
+		}
+		if !sameIDs(visible, req.RecipeIDs) {
+			mismatch = true
+			return nil
+		}
+
+		order, err := collectionOrder(tx, collection.ID)
+		if err != nil {
+			return err
+		}
+		for _, id := range req.RecipeIDs {
+			order = removeID(order, id)
+		}
+		return saveCollectionOrder(tx, collection.ID, append(req.RecipeIDs, order...))
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to reorder collection")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to reorder collection"})
+		return
+	}
+	if mismatch {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recipe_ids must list every recipe in the collection exactly once"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Collection reordered"})
+}
+
+// ExportCollection exports a collection and its recipes, in order, as a single
+// JSON or text document.
+func (h *Handler) ExportCollection(c *gin.Context) {
+	format := c.Param("format")
+	if format != "json" && format != "txt" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export format"})
+		return
+	}
+
+	system, ok := unitSystemParam(c)
+	if !ok {
+		return
+	}
+
+	collection, ok := h.ownedCollection(c)
+	if !ok {
+		return
+	}
+
+	var recipes []models.Recipe
+	if err := h.withStructure(collectionRecipes(h.db, collection.ID)).Find(&recipes).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch collection recipes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export collection"})
+		return
+	}
+	for i := range recipes {
+		convertRecipe(&recipes[i], system)
+	}
+
+	filename := "collection_" + time.Now().Format("20060102_150405")
+
+	if format == "json" {
+		jsonData, err := json.MarshalIndent(gin.H{
+			"collection":  collection,
+			"recipes":     recipes,
+			"exported_at": time.Now(),
+		}, "", "  ")
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export collection"})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+		c.Data(http.StatusOK, "application/json", jsonData)
+		return
+	}
+
+	var text strings.Builder
+	text.WriteString(collection.Name + "\n")
+	if collection.Description != "" {
+		text.WriteString("\n" + collection.Description + "\n")
+	}
+	text.WriteString("\nContents\n")
+	for i, recipe := range recipes {
+		fmt.Fprintf(&text, "%d. %s\n", i+1, recipe.Title)
+	}
+	for _, recipe := range recipes {
+		fmt.Fprintf(&text, `
+========================================
+
+Ingredients Used: %s
+Dietary Restrictions: %s
+Cuisine Preference: %s
+Serving Size: %d
+
+%s
+`, recipe.IngredientsUsed,
+			getStringValue(deref(recipe.DietaryRestrictions), "None"),
+			getStringValue(deref(recipe.CuisinePreference), "Any"),
+			recipe.ServingSize, strings.TrimSpace(recipe.RecipeContent))
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.txt"`, filename))
+	c.Data(http.StatusOK, "text/plain", []byte(text.String()))
+}
+
+// ownedCollection loads the :id collection if it belongs to the caller,
+// writing a 404 response and returning false when it does not.
+func (h *Handler) ownedCollection(c *gin.Context) (*models.Collection, bool) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid collection ID"})
+		return nil, false
+	}
+
+	var collection models.Collection
+	if err := h.db.Where("user_id = ?", c.GetUint("user_id")).First(&collection, id.(uint)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Collection not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch collection")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch collection"})
+		}
+		return nil, false
+	}
+	return &collection, true
+}
+
+// applyCollectionRequest validates req and copies the fields it sets onto
+// collection, writing an error response and returning false when invalid.
+func (h *Handler) applyCollectionRequest(c *gin.Context, collection *models.Collection, req CollectionRequest) bool {
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" || len(name) > maxCollectionName {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Collection name must be between 1 and 100 characters"})
+			return false
+		}
+
+		var existing int64
+		err := h.db.Model(&models.Collection{}).
+			Where("user_id = ? AND name = ? AND id <> ?", collection.UserID, name, collection.ID).
+			Count(&existing).Error
+		if err != nil {
+			logrus.WithError(err).Error("Failed to check for existing collection")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save collection"})
+			return false
+		}
+		if existing > 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already have a collection with this name"})
+			return false
+		}
+		collection.Name = name
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len(description) > maxCollectionDescription {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Collection description cannot be longer than 2000 characters"})
+			return false
+		}
+		collection.Description = description
+	}
+
+	if req.CoverRecipeID != nil {
+		if *req.CoverRecipeID == 0 {
+			collection.CoverRecipeID = nil
+		} else {
+			if !h.checkOwnedRecipe(c, *req.CoverRecipeID) {
+				return false
+			}
+			collection.CoverRecipeID = req.CoverRecipeID
+		}
+	}
+
+	return true
+}
+
+// checkOwnedRecipe checks that a recipe given in a request body belongs to the
+// caller, writing a 404 response and returning false when it does not.
+func (h *Handler) checkOwnedRecipe(c *gin.Context, recipeID uint) bool {
+	var count int64
+	if err := h.ownedRecipes(c).Where("id = ?", recipeID).Count(&count).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe"})
+		return false
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Recipe %d not found", recipeID)})
+		return false
+	}
+	return true
+}
+
+// collectionRecipes selects the recipes in a collection in collection order,
+// leaving out trashed recipes.
+func collectionRecipes(db *gorm.DB, collectionID uint) *gorm.DB {
+	return db.Model(&models.Recipe{}).
+		Joins("JOIN collection_recipes ON collection_recipes.recipe_id = recipes.id").
+		Where("collection_recipes.collection_id = ?", collectionID).
+		Order("collection_recipes.position, recipes.id")
+}
+
+// collectionOrder returns the IDs of every recipe in a collection, including
+// trashed ones, in collection order.
+func collectionOrder(tx *gorm.DB, collectionID uint) ([]uint, error) {
+	var ids []uint
+	err := tx.Model(&models.CollectionRecipe{}).
+		Where("collection_id = ?", collectionID).
+		Order("position, recipe_id").
+		Pluck("recipe_id", &ids).Error
+	return ids, err
+}
+
+func saveCollectionOrder(tx *gorm.DB, collectionID uint, order []uint) error {
+	for position, recipeID := range order {
+		err := tx.Model(&models.CollectionRecipe{}).
+			Where("collection_id = ? AND recipe_id = ?", collectionID, recipeID).
+			Update("position", position).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func removeID(ids []uint, id uint) []uint {
+	kept := ids[:0]
+	for _, existing := range ids {
+		if existing != id {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
+
+func sameIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	seen := make(map[uint]bool, len(a))
+	for _, id := range a {
+		seen[id] = true
+	}
+	for _, id := range b {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
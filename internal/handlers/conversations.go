@@ -0,0 +1,456 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/llm"
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/structured"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxRefinements            = 20
+	maxRefinementLength       = 1000
+	conversationRoleUser      = "user"
+	conversationRoleAssistant = "assistant"
+
+	// The starting recipe and request are re-sent to the model on every turn,
+	// so they are bounded like the refinements that follow them.
+	maxConversationRecipeLength      = 20000
+	maxConversationIngredientsLength = 2000
+	maxConversationPreferenceLength  = 100
+)
+
+// StartConversationRequest starts refining either a saved recipe or one just
+// returned by /generate_recipe.
+type StartConversationRequest struct {
+	RecipeID   *uint       `json:"recipe_id"`
+	RecipeData *RecipeData `json:"recipe_data"`
+}
+
+// ConversationMessageResponse adds the structured recipe to assistant turns
+// that have one.
+type ConversationMessageResponse struct {
+	models.ConversationMessage
+	Recipe *structured.Recipe `json:"recipe,omitempty"`
+}
+
+func (h *Handler) ListConversations(c *gin.Context) {
+	conversations := []models.Conversation{}
+	err := h.db.Where("user_id = ?", c.GetUint("user_id")).
+		Order("updated_at DESC").
+		Find(&conversations).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch conversations")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"conversations": conversations})
+}
+
+// StartConversation opens a conversation whose first turns are the original
+// recipe request and the recipe itself.
+func (h *Handler) StartConversation(c *gin.Context) {
+	var req StartConversationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	var conversation models.Conversation
+	var reply models.ConversationMessage
+	switch {
+	case req.RecipeID != nil:
+		var recipe models.Recipe
+		if err := h.ownedRecipes(c).First(&recipe, *req.RecipeID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+			} else {
+				logrus.WithError(err).Error("Failed to fetch recipe for conversation")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe"})
+			}
+			return
+		}
+		conversation = models.Conversation{
+			RecipeID:            &recipe.ID,
+			IngredientsUsed:     recipe.IngredientsUsed,
+			DietaryRestrictions: deref(recipe.DietaryRestrictions),
+			CuisinePreference:   deref(recipe.CuisinePreference),
+			ServingSize:         recipe.ServingSize,
+		}
+		reply = models.ConversationMessage{Content: recipe.RecipeContent}
+
+	case req.RecipeData != nil && req.RecipeData.Recipe != "":
+		data := req.RecipeData
+		conversation = models.Conversation{
+			IngredientsUsed:     data.IngredientsUsed,
+			DietaryRestrictions: data.DietaryRestrictions,
+			CuisinePreference:   data.CuisinePreference,
+			ServingSize:         data.ServingSize,
+		}
+		reply = models.ConversationMessage{Content: data.Recipe}
+		if data.Structured != nil {
+			encoded, err := json.Marshal(data.Structured)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid structured recipe"})
+				return
+			}
+			reply.Structured = string(encoded)
+		}
+
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide a recipe_id or recipe_data"})
+		return
+	}
+
+	switch {
+	case len(reply.Content) > maxConversationRecipeLength || len(reply.Structured) > maxConversationRecipeLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("recipe cannot be longer than %d characters", maxConversationRecipeLength)})
+		return
+	case len(conversation.IngredientsUsed) > maxConversationIngredientsLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ingredients_used cannot be longer than %d characters", maxConversationIngredientsLength)})
+		return
+	case len(conversation.DietaryRestrictions) > maxConversationPreferenceLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("dietary_restrictions cannot be longer than %d characters", maxConversationPreferenceLength)})
+		return
+	case len(conversation.CuisinePreference) > maxConversationPreferenceLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cuisine_preference cannot be longer than %d characters", maxConversationPreferenceLength)})
+		return
+	}
+
+	if conversation.ServingSize <= 0 {
+		conversation.ServingSize = 4
+	}
+	conversation.UserID = c.GetUint("user_id")
+	prompt := buildRecipePrompt(RecipeRequest{
+		Ingredients:         conversation.IngredientsUsed,
+		DietaryRestrictions: conversation.DietaryRestrictions,
+		CuisinePreference:   conversation.CuisinePreference,
+		ServingSize:         conversation.ServingSize,
+	})
+	reply.Position = 1
+	reply.Role = conversationRoleAssistant
+	conversation.Messages = []models.ConversationMessage{
+		{Position: 0, Role: conversationRoleUser, Content: prompt},
+		reply,
+	}
+
+	if err := h.db.Create(&conversation).Error; err != nil {
+		logrus.WithError(err).Error("Failed to create conversation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start conversation"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"conversation_id": conversation.ID,
+		"recipe_id":       conversation.RecipeID,
+		"user_id":         conversation.UserID,
+	}).Info("Conversation started")
+
+	respondConversation(c, http.StatusCreated, &conversation)
+}
+
+func (h *Handler) GetConversation(c *gin.Context) {
+	conversation, ok := h.ownedConversation(c)
+	if !ok {
+		return
+	}
+
+	respondConversation(c, http.StatusOK, conversation)
+}
+
+// RefineRecipe sends a follow-up such as "make it spicier" with the whole
+// conversation so far and returns the revised recipe. The reply's recipe_data
+// can be passed to /save_recipe as is.
+func (h *Handler) RefineRecipe(c *gin.Context) {
+	conversation, ok := h.ownedConversation(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Message string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" || len(message) > maxRefinementLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("message must be between 1 and %d characters", maxRefinementLength)})
+		return
+	}
+	if len(conversation.Messages) >= 2*(maxRefinements+1) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A conversation can have at most %d refinements; start a new one from the latest recipe", maxRefinements)})
+		return
+	}
+
+	history := make([]llm.Message, 0, len(conversation.Messages)+1)
+	for _, turn := range conversation.Messages {
+		history = append(history, llm.Message{Role: turn.Role, Content: conversationPrompt(turn)})
+	}
+	next := len(conversation.Messages)
+	asked := models.ConversationMessage{
+		ConversationID: conversation.ID,
+		Position:       next,
+		Role:           conversationRoleUser,
+		Content:        message,
+	}
+	request := llm.Conversation(append(history, llm.Message{Role: asked.Role, Content: conversationPrompt(asked)}))
+
+	logrus.WithFields(logrus.Fields{
+		"conversation_id": conversation.ID,
+		"turns":           len(request.Messages),
+		"provider":        h.llm.Name(),
+	}).Info("Calling LLM provider for recipe refinement")
+
+	recipe, err := h.generateStructuredRecipe(c.Request.Context(), request, false)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"provider":        h.llm.Name(),
+			"model":           h.llm.Model(),
+			"conversation_id": conversation.ID,
+		}).Error("Failed to refine recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to refine recipe: %v", err)})
+		return
+	}
+
+	encoded, err := json.Marshal(recipe)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to encode refined recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refine recipe"})
+		return
+	}
+	reply := models.ConversationMessage{
+		ConversationID: conversation.ID,
+		Position:       next + 1,
+		Role:           conversationRoleAssistant,
+		Content:        recipe.Render(),
+		Structured:     string(encoded),
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&[]models.ConversationMessage{asked, reply}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Conversation{ID: conversation.ID}).Update("updated_at", time.Now()).Error
+	})
+	if isUniqueViolation(err) {
+		// Another follow-up to this conversation was stored while this one
+		// was being answered, and took its position.
+		c.JSON(http.StatusConflict, gin.H{"error": "The conversation changed while this follow-up was being answered; reload it and send the follow-up again"})
+		return
+	}
+	if err != nil {
+		logrus.WithError(err).Error("Failed to store conversation turn")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store conversation"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"conversation_id": conversation.ID,
+		"position":        reply.Position,
+		"title":           recipe.Name,
+	}).Info("Recipe refined")
+
+	recipeData := newRecipeData(RecipeRequest{
+		Ingredients:         conversation.IngredientsUsed,
+		DietaryRestrictions: conversation.DietaryRestrictions,
+		CuisinePreference:   conversation.CuisinePreference,
+		ServingSize:         conversation.ServingSize,
+	}, reply.Content)
+	recipeData.Structured = recipe
+
+	c.JSON(http.StatusOK, gin.H{
+		"conversation_id": conversation.ID,
+		"message":         ConversationMessageResponse{ConversationMessage: reply, Recipe: recipe},
+		"recipe_data":     recipeData,
+	})
+}
+
+// SaveConversationTurn saves the recipe from an assistant turn as a new
+// revision of a saved recipe: recipe_id from the body, or else the recipe the
+// conversation is linked to.
+func (h *Handler) SaveConversationTurn(c *gin.Context) {
+	conversation, ok := h.ownedConversation(c)
+	if !ok {
+		return
+	}
+
+	position, err := strconv.Atoi(c.Param("position"))
+	if err != nil || position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Position must be a whole number"})
+		return
+	}
+	if position >= len(conversation.Messages) {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Message %d not found", position)})
+		return
+	}
+	turn := conversation.Messages[position]
+	if turn.Role != conversationRoleAssistant {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only recipe replies can be saved"})
+		return
+	}
+
+	var req struct {
+		RecipeID *uint `json:"recipe_id"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	recipeID := conversation.RecipeID
+	if req.RecipeID != nil {
+		recipeID = req.RecipeID
+	}
+	if recipeID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recipe_id is required; save the recipe first with /save_recipe"})
+		return
+	}
+	if !h.checkOwnedRecipe(c, *recipeID) {
+		return
+	}
+
+	updates := map[string]interface{}{
+		"recipe_content": turn.Content,
+		"updated_at":     time.Now(),
+	}
+	recipe := messageRecipe(turn)
+	if recipe != nil {
+		updates["title"] = recipe.Name
+		if recipe.Servings > 0 {
+			updates["serving_size"] = recipe.Servings
+		}
+	}
+
+	var revision *models.RecipeRevision
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if revision, err = applyRecipeUpdate(tx, *recipeID, updates, recipe, c.GetUint("user_id")); err != nil {
+			return err
+		}
+		return tx.Model(&models.Conversation{ID: conversation.ID}).Update("recipe_id", *recipeID).Error
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("recipe_id", *recipeID).Error("Failed to save conversation turn")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recipe"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"conversation_id": conversation.ID,
+		"position":        position,
+		"recipe_id":       *recipeID,
+		"revision":        revision.Revision,
+		"user_id":         c.GetUint("user_id"),
+	}).Info("Conversation turn saved")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   fmt.Sprintf("Saved as revision %d", revision.Revision),
+		"recipe_id": *recipeID,
+		"revision":  revision.Revision,
+	})
+}
+
+func (h *Handler) DeleteConversation(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	result := h.db.Where("user_id = ?", c.GetUint("user_id")).Delete(&models.Conversation{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete conversation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete conversation"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation deleted successfully"})
+}
+
+// ownedConversation loads the :id conversation with its messages in order if
+// it belongs to the caller, writing a 404 response and returning false when
+// it does not.
+func (h *Handler) ownedConversation(c *gin.Context) (*models.Conversation, bool) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return nil, false
+	}
+
+	var conversation models.Conversation
+	err := h.db.Preload("Messages", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where("user_id = ?", c.GetUint("user_id")).
+		First(&conversation, id.(uint)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch conversation")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversation"})
+		}
+		return nil, false
+	}
+	return &conversation, true
+}
+
+func respondConversation(c *gin.Context, status int, conversation *models.Conversation) {
+	messages := make([]ConversationMessageResponse, 0, len(conversation.Messages))
+	for _, message := range conversation.Messages {
+		messages = append(messages, ConversationMessageResponse{ConversationMessage: message, Recipe: messageRecipe(message)})
+	}
+
+	c.JSON(status, gin.H{
+		"conversation": conversation,
+		"messages":     messages,
+	})
+}
+
+// conversationPrompt is what the model sees for a turn. Follow-ups are
+// wrapped so the model always answers with the complete revised recipe.
+func conversationPrompt(message models.ConversationMessage) string {
+	if message.Role != conversationRoleUser || message.Position == 0 {
+		return message.Content
+	}
+	return fmt.Sprintf(`Revise the recipe above as follows: %s
+
+Keep everything else the same unless the change requires it, and give the complete revised recipe.`, message.Content)
+}
+
+// messageRecipe decodes the structured recipe of an assistant turn, or
+// returns nil for turns without one.
+func messageRecipe(message models.ConversationMessage) *structured.Recipe {
+	if message.Structured == "" {
+		return nil
+	}
+	var recipe structured.Recipe
+	if err := json.Unmarshal([]byte(message.Structured), &recipe); err != nil {
+		return nil
+	}
+	return &recipe
+}
+
+// isUniqueViolation reports whether err is Postgres rejecting a row that
+// breaks a unique index.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
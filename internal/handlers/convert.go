@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/structured"
+	"recipe-ai/internal/units"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unitSystemParam reads the optional units=metric|imperial query parameter,
+// writing a 400 response and returning false when it is invalid.
+func unitSystemParam(c *gin.Context) (units.System, bool) {
+	system, err := units.ParseSystem(c.Query("units"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return units.SystemNone, false
+	}
+	return system, true
+}
+
+func convertRecipe(recipe *models.Recipe, system units.System) {
+	if system == units.SystemNone {
+		return
+	}
+
+	recipe.RecipeContent = units.ConvertContent(recipe.RecipeContent, system)
+	for i := range recipe.Ingredients {
+		ingredient := &recipe.Ingredients[i]
+		if quantity, unit, ok := units.ConvertText(ingredient.Quantity, ingredient.Unit, ingredient.Item, system); ok {
+			ingredient.Quantity = quantity
+			ingredient.Unit = unit
+		}
+	}
+	for i := range recipe.Steps {
+		recipe.Steps[i].Instruction = units.ConvertTemperatures(recipe.Steps[i].Instruction, system)
+	}
+}
+
+func convertRecipeData(data *RecipeData, system units.System) {
+	if system == units.SystemNone {
+		return
+	}
+
+	data.Recipe = units.ConvertContent(data.Recipe, system)
+	if data.Structured == nil {
+		return
+	}
+
+	converted := *data.Structured
+	converted.Ingredients = make([]structured.Ingredient, len(data.Structured.Ingredients))
+	for i, ingredient := range data.Structured.Ingredients {
+		if quantity, unit, ok := units.ConvertText(ingredient.Quantity, ingredient.Unit, ingredient.Item, system); ok {
+			ingredient.Quantity = quantity
+			ingredient.Unit = unit
+		}
+		converted.Ingredients[i] = ingredient
+	}
+	converted.Steps = make([]string, len(data.Structured.Steps))
+	for i, step := range data.Structured.Steps {
+		converted.Steps[i] = units.ConvertTemperatures(step, system)
+	}
+	data.Structured = &converted
+}
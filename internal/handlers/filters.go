@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type recipeFilters struct {
+	Search       string
+	MinRating    int
+	Cuisines     []string
+	Dietary      []string
+	MinServings  int
+	MaxServings  int
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	UpdatedFrom  *time.Time
+	UpdatedTo    *time.Time
+	CollectionID int
+	Tags         []string
+	AnyTag       bool
+	Facets       bool
+}
+
+type facetCount struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// dietaryTokens splits a stored dietary_restrictions value such as
+// "Vegan, Gluten-Free" into lower-case tokens so filters match whole values.
+const dietaryTokens = `string_to_array(lower(replace(dietary_restrictions, ' ', '')), ',')`
+
+func parseRecipeFilters(c *gin.Context) (recipeFilters, error) {
+	var f recipeFilters
+	var err error
+
+	f.Search = strings.TrimSpace(c.Query("search"))
+	f.Cuisines = multiValueQuery(c, "cuisine_preference")
+	f.Dietary = multiValueQuery(c, "dietary_restrictions")
+	for i, value := range f.Dietary {
+		f.Dietary[i] = strings.ReplaceAll(value, " ", "")
+	}
+
+	if f.MinRating, err = intQuery(c, "min_rating", 1, 5); err != nil {
+		return f, err
+	}
+	if f.MinServings, err = intQuery(c, "min_servings", 1, maxScaledServings); err != nil {
+		return f, err
+	}
+	if f.MaxServings, err = intQuery(c, "max_servings", 1, maxScaledServings); err != nil {
+		return f, err
+	}
+	if f.MinServings > 0 && f.MaxServings > 0 && f.MinServings > f.MaxServings {
+		return f, fmt.Errorf("min_servings cannot be greater than max_servings")
+	}
+
+	if f.CreatedFrom, err = dateQuery(c, "created_from", false); err != nil {
+		return f, err
+	}
+	if f.CreatedTo, err = dateQuery(c, "created_to", true); err != nil {
+		return f, err
+	}
+	if f.UpdatedFrom, err = dateQuery(c, "updated_from", false); err != nil {
+		return f, err
+	}
+	if f.UpdatedTo, err = dateQuery(c, "updated_to", true); err != nil {
+		return f, err
+	}
+	if f.CreatedFrom != nil && f.CreatedTo != nil && f.CreatedFrom.After(*f.CreatedTo) {
+		return f, fmt.Errorf("created_from cannot be after created_to")
+	}
+	if f.UpdatedFrom != nil && f.UpdatedTo != nil && f.UpdatedFrom.After(*f.UpdatedTo) {
+		return f, fmt.Errorf("updated_from cannot be after updated_to")
+	}
+
+	if f.CollectionID, err = intQuery(c, "collection_id", 1, math.MaxInt32); err != nil {
+		return f, err
+	}
+
+	if f.Tags, err = normalizeTags(multiValueQuery(c, "tags")); err != nil {
+		return f, err
+	}
+	switch c.DefaultQuery("tags_match", "all") {
+	case "all":
+	case "any":
+		f.AnyTag = true
+	default:
+		return f, fmt.Errorf("tags_match must be all or any")
+	}
+
+	if facets := c.Query("facets"); facets != "" {
+		if f.Facets, err = strconv.ParseBool(facets); err != nil {
+			return f, fmt.Errorf("facets must be true or false")
+		}
+	}
+
+	return f, nil
+}
+
+// apply adds every filter except the search term, which callers combine with
+// either full-text or fuzzy matching.
+func (f recipeFilters) apply(query *gorm.DB) *gorm.DB {
+	if f.MinRating > 0 {
+		query = query.Where("rating >= ?", f.MinRating)
+	}
+	if len(f.Cuisines) > 0 {
+		query = query.Where("lower(cuisine_preference) IN ?", f.Cuisines)
+	}
+	for _, dietary := range f.Dietary {
+		query = query.Where("? = ANY("+dietaryTokens+")", dietary)
+	}
+	if f.MinServings > 0 {
+		query = query.Where("serving_size >= ?", f.MinServings)
+	}
+	if f.MaxServings > 0 {
+		query = query.Where("serving_size <= ?", f.MaxServings)
+	}
+	if f.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedTo != nil {
+		query = query.Where("created_at <= ?", *f.CreatedTo)
+	}
+	if f.UpdatedFrom != nil {
+		query = query.Where("updated_at >= ?", *f.UpdatedFrom)
+	}
+	if f.UpdatedTo != nil {
+		query = query.Where("updated_at <= ?", *f.UpdatedTo)
+	}
+	if len(f.Tags) > 0 {
+		tagged := "SELECT recipe_tags.recipe_id FROM recipe_tags JOIN tags ON tags.id = recipe_tags.tag_id WHERE tags.name IN ?"
+		if f.AnyTag {
+			query = query.Where("recipes.id IN ("+tagged+")", f.Tags)
+		} else {
+			query = query.Where("recipes.id IN ("+tagged+" GROUP BY recipe_tags.recipe_id HAVING COUNT(*) = ?)", f.Tags, len(f.Tags))
+		}
+	}
+	if f.CollectionID > 0 {
+		query = query.Where("recipes.id IN (SELECT recipe_id FROM collection_recipes WHERE collection_id = ?)", f.CollectionID)
+	}
+	return query
+}
+
+func (f recipeFilters) logFields() logrus.Fields {
+	return logrus.Fields{
+		"search":               f.Search,
+		"min_rating":           f.MinRating,
+		"cuisine_preference":   f.Cuisines,
+		"dietary_restrictions": f.Dietary,
+		"min_servings":         f.MinServings,
+		"max_servings":         f.MaxServings,
+		"collection_id":        f.CollectionID,
+		"tags":                 f.Tags,
+		"tags_match_any":       f.AnyTag,
+	}
+}
+
+// recipeFacets counts the recipes matched by query per cuisine, dietary
+// restriction and rating, for building filter sidebars.
+func (h *Handler) recipeFacets(query func() *gorm.DB) (gin.H, error) {
+	var cuisines, dietary, ratings []facetCount
+
+	err := query().
+		Select("COALESCE(NULLIF(lower(cuisine_preference), ''), 'any') AS value, COUNT(*) AS count").
+		Group("value").Order("count DESC, value").
+		Scan(&cuisines).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Set-returning functions cannot be grouped directly, so unnest first.
+	tokens := query().
+		Select("unnest(" + dietaryTokens + ") AS value").
+		Where("dietary_restrictions IS NOT NULL AND dietary_restrictions <> ''")
+	err = h.db.Table("(?) AS dietary", tokens).
+		Select("value, COUNT(*) AS count").
+		Where("value <> ''").
+		Group("value").Order("count DESC, value").
+		Scan(&dietary).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = query().
+		Select("COALESCE(rating::text, 'unrated') AS value, COUNT(*) AS count").
+		Group("value").Order("value DESC").
+		Scan(&ratings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return gin.H{
+		"cuisine_preference":   cuisines,
+		"dietary_restrictions": dietary,
+		"rating":               ratings,
+	}, nil
+}
+
+func multiValueQuery(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+func intQuery(c *gin.Context, key string, min, max int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return 0, nil
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min || value > max {
+		return 0, fmt.Errorf("%s must be a whole number between %d and %d", key, min, max)
+	}
+	return value, nil
+}
+
+// dateQuery accepts either a date (YYYY-MM-DD) or an RFC 3339 timestamp. Date
+// upper bounds cover the whole day.
+func dateQuery(c *gin.Context, key string, endOfDay bool) (*time.Time, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD) or RFC 3339 timestamp", key)
+	}
+	if endOfDay {
+		t = t.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &t, nil
+}
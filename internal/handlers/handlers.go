@@ -1,17 +1,21 @@
 package handlers
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
+	"recipe-ai/internal/auth"
 	"recipe-ai/internal/config"
+	"recipe-ai/internal/llm"
 	"recipe-ai/internal/models"
+	"recipe-ai/internal/recipeparse"
+	"recipe-ai/internal/structured"
 
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
@@ -23,47 +27,49 @@ import (
 type Handler struct {
 	db            *gorm.DB
 	cfg           *config.Config
+	llm           llm.Provider
+	sessions      *auth.SessionManager
+	oidc          *auth.OIDCProvider
 	totalRecipes  prometheus.Gauge
 	dbConnections prometheus.GaugeVec
 }
 
-type AnthropicMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
-}
-
-type AnthropicRequest struct {
-	Model       string             `json:"model"`
-	MaxTokens   int                `json:"max_tokens"`
-	Temperature float64            `json:"temperature"`
-	Messages    []AnthropicMessage `json:"messages"`
-}
-
-type AnthropicResponse struct {
-	Content []struct {
-		Text string `json:"text"`
-		Type string `json:"type"`
-	} `json:"content"`
-}
-
 type RecipeRequest struct {
-	Ingredients         string `json:"ingredients"`
-	DietaryRestrictions string `json:"dietary_restrictions"`
-	CuisinePreference   string `json:"cuisine_preference"`
-	ServingSize         int    `json:"serving_size"`
+	Ingredients         string `json:"ingredients" form:"ingredients"`
+	DietaryRestrictions string `json:"dietary_restrictions" form:"dietary_restrictions"`
+	CuisinePreference   string `json:"cuisine_preference" form:"cuisine_preference"`
+	ServingSize         int    `json:"serving_size" form:"serving_size"`
+	SuggestTags         bool   `json:"suggest_tags" form:"suggest_tags"`
+	UsePantry           bool   `json:"use_pantry" form:"use_pantry"`
+	Variations          int    `json:"variations" form:"variations"`
 }
 
 type RecipeData struct {
-	Recipe              string `json:"recipe"`
-	Timestamp           string `json:"timestamp"`
-	IngredientsUsed     string `json:"ingredients_used"`
-	DietaryRestrictions string `json:"dietary_restrictions"`
-	CuisinePreference   string `json:"cuisine_preference"`
-	ServingSize         int    `json:"serving_size"`
+	Recipe              string             `json:"recipe"`
+	Structured          *structured.Recipe `json:"structured,omitempty"`
+	// Tags are the tags suggested for a streamed recipe, which has no
+	// structured form to carry them.
+	Tags                []string           `json:"tags,omitempty"`
+	Timestamp           string             `json:"timestamp"`
+	IngredientsUsed     string             `json:"ingredients_used"`
+	DietaryRestrictions string             `json:"dietary_restrictions"`
+	CuisinePreference   string             `json:"cuisine_preference"`
+	ServingSize         int                `json:"serving_size"`
+	Pantry              *PantryUsage       `json:"pantry,omitempty"`
+}
+
+// RecipeListItem is a recipe as returned by GetRecipes. Rank and Headline are
+// only populated for full-text searches, Similarity for fuzzy fallbacks.
+type RecipeListItem struct {
+	models.Recipe
+	Rank       *float64 `json:"rank,omitempty" gorm:"->;column:rank"`
+	Headline   string   `json:"headline,omitempty" gorm:"->;column:headline"`
+	Similarity *float64 `json:"similarity,omitempty" gorm:"->;column:similarity"`
 }
 
 type SaveRecipeRequest struct {
 	RecipeData RecipeData `json:"recipe_data"`
+	Tags       []string   `json:"tags"`
 }
 
 type ExportRecipeRequest struct {
@@ -74,10 +80,14 @@ type ValidateIngredientsRequest struct {
 	Ingredients string `json:"ingredients"`
 }
 
-func New(db *gorm.DB, cfg *config.Config) *Handler {
+// New creates the handlers. oidc is nil when single sign-on is not configured.
+func New(db *gorm.DB, cfg *config.Config, provider llm.Provider, sessions *auth.SessionManager, oidc *auth.OIDCProvider) *Handler {
 	h := &Handler{
-		db:  db,
-		cfg: cfg,
+		db:       db,
+		cfg:      cfg,
+		llm:      provider,
+		sessions: sessions,
+		oidc:     oidc,
 	}
 
 	h.totalRecipes = prometheus.NewGauge(prometheus.GaugeOpts{
@@ -159,15 +169,112 @@ func (h *Handler) GenerateRecipe(c *gin.Context) {
 		return
 	}
 
-	if req.Ingredients == "" {
+	if req.Ingredients == "" && !req.UsePantry {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide at least one ingredient"})
 		return
 	}
 
+	if req.Variations < 0 || req.Variations > maxVariations {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("variations must be between 1 and %d", maxVariations)})
+		return
+	}
+
+	// Cooking from the pantry offers the model the caller's pantry on top of
+	// any ingredients they listed.
+	pantry, ok := h.recipePantry(c, &req)
+	if !ok {
+		return
+	}
+
 	if req.ServingSize == 0 {
 		req.ServingSize = 4
 	}
 
+	prompt := buildRecipePrompt(req)
+	if req.UsePantry {
+		prompt = buildPantryPrompt(prompt, pantry)
+	}
+
+	if req.Variations > 0 {
+		h.generateVariations(c, req, prompt, pantry)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"ingredients_count": len(strings.Split(req.Ingredients, ",")),
+		"serving_size":      req.ServingSize,
+		"cuisine":           req.CuisinePreference,
+		"dietary":           req.DietaryRestrictions,
+		"use_pantry":        req.UsePantry,
+		"provider":          h.llm.Name(),
+	}).Info("Calling LLM provider for recipe generation")
+
+	recipe, err := h.generateStructuredRecipe(c.Request.Context(), llm.UserPrompt(prompt), req.SuggestTags)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"provider": h.llm.Name(),
+			"model":    h.llm.Model(),
+			"ip":    c.ClientIP(),
+		}).Error("Failed to generate recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate recipe: %v", err)})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"title":       recipe.Name,
+		"ingredients": len(recipe.Ingredients),
+		"steps":       len(recipe.Steps),
+		"ip":          c.ClientIP(),
+	}).Info("Recipe generated successfully")
+
+	recipeData := newRecipeData(req, recipe.Render())
+	recipeData.Structured = recipe
+	if req.UsePantry {
+		recipeData.Pantry = pantryUsage(recipe, pantry, req.Ingredients)
+	}
+	c.JSON(http.StatusOK, recipeData)
+}
+
+// generateStructuredRecipe asks the model for a recipe in reply to req, plus
+// suggested tags when suggestTags is set.
+func (h *Handler) generateStructuredRecipe(ctx context.Context, req llm.Request, suggestTags bool) (*structured.Recipe, error) {
+	tool := llm.Tool{
+		Name:        structured.ToolName,
+		Description: "Record the generated recipe in a structured format.",
+		Schema:      structured.Schema(),
+	}
+	if suggestTags {
+		tool.Schema = structured.SchemaWithTags()
+	}
+
+	data, err := h.llm.CompleteJSON(ctx, req, tool)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := structured.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe.Tags = suggestedTags(recipe.Tags)
+	return recipe, nil
+}
+
+// suggestedTags normalizes the tags the model suggested. Suggestions are only
+// a convenience, so any that would not be valid tags are quietly dropped
+// rather than failing the whole generation.
+func suggestedTags(suggestions []string) []string {
+	tags := make([]string, 0, len(suggestions))
+	for _, suggestion := range suggestions {
+		if tag, err := models.NormalizeTag(suggestion); err == nil && !slices.Contains(tags, tag) && len(tags) < structured.MaxTags {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func buildRecipePrompt(req RecipeRequest) string {
 	dietaryText := "None"
 	if req.DietaryRestrictions != "" {
 		dietaryText = req.DietaryRestrictions
@@ -178,7 +285,7 @@ func (h *Handler) GenerateRecipe(c *gin.Context) {
 		cuisineText = req.CuisinePreference
 	}
 
-	prompt := fmt.Sprintf(`Generate a detailed recipe using the following ingredients: %s
+	return fmt.Sprintf(`Generate a detailed recipe using the following ingredients: %s
 
 Dietary restrictions: %s
 Cuisine preference: %s
@@ -193,30 +300,10 @@ Please provide:
 6. Tips or variations
 
 Format the response in a clear, structured way.`, req.Ingredients, dietaryText, cuisineText, req.ServingSize)
+}
 
-	logrus.WithFields(logrus.Fields{
-		"ingredients_count": len(strings.Split(req.Ingredients, ",")),
-		"serving_size":      req.ServingSize,
-		"cuisine":           req.CuisinePreference,
-		"dietary":           req.DietaryRestrictions,
-	}).Info("Calling Anthropic API for recipe generation")
-
-	recipeText, err := h.callAnthropicAPI(prompt)
-	if err != nil {
-		logrus.WithError(err).WithFields(logrus.Fields{
-			"model": h.cfg.ClaudeModel,
-			"ip":    c.ClientIP(),
-		}).Error("Failed to generate recipe")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to generate recipe: %v", err)})
-		return
-	}
-
-	logrus.WithFields(logrus.Fields{
-		"response_length": len(recipeText),
-		"ip":              c.ClientIP(),
-	}).Info("Recipe generated successfully")
-
-	recipeData := RecipeData{
+func newRecipeData(req RecipeRequest, recipeText string) RecipeData {
+	return RecipeData{
 		Recipe:              recipeText,
 		Timestamp:           time.Now().Format(time.RFC3339),
 		IngredientsUsed:     req.Ingredients,
@@ -224,8 +311,6 @@ Format the response in a clear, structured way.`, req.Ingredients, dietaryText,
 		CuisinePreference:   req.CuisinePreference,
 		ServingSize:         req.ServingSize,
 	}
-
-	c.JSON(http.StatusOK, recipeData)
 }
 
 func (h *Handler) SaveRecipe(c *gin.Context) {
@@ -247,12 +332,28 @@ func (h *Handler) SaveRecipe(c *gin.Context) {
 		return
 	}
 
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := c.GetUint("user_id")
 	recipe := models.Recipe{
+		UserID:          &userID,
 		RecipeContent:   req.RecipeData.Recipe,
 		IngredientsUsed: req.RecipeData.IngredientsUsed,
 		ServingSize:     req.RecipeData.ServingSize,
 	}
 
+	if req.RecipeData.Structured != nil {
+		recipe.Title = req.RecipeData.Structured.Name
+	}
+
+	ingredients, steps := recipeStructure(req.RecipeData)
+	recipe.Ingredients = models.NewRecipeIngredients(ingredients)
+	recipe.Steps = models.NewRecipeSteps(steps)
+
 	if req.RecipeData.DietaryRestrictions != "" {
 		recipe.DietaryRestrictions = &req.RecipeData.DietaryRestrictions
 	}
@@ -261,7 +362,16 @@ func (h *Handler) SaveRecipe(c *gin.Context) {
 		recipe.CuisinePreference = &req.RecipeData.CuisinePreference
 	}
 
-	if err := h.db.Create(&recipe).Error; err != nil {
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&recipe).Error; err != nil {
+			return err
+		}
+		if _, err := models.RecordRevision(tx, recipe.ID, &userID); err != nil {
+			return err
+		}
+		return models.AddRecipeTags(tx, userID, recipe.ID, tags)
+	})
+	if err != nil {
 		logrus.WithError(err).WithField("ip", c.ClientIP()).Error("Failed to save recipe")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save recipe"})
 		return
@@ -270,6 +380,7 @@ func (h *Handler) SaveRecipe(c *gin.Context) {
 	logrus.WithFields(logrus.Fields{
 		"recipe_id": recipe.ID,
 		"title":     recipe.Title,
+		"user_id":   userID,
 		"ip":        c.ClientIP(),
 	}).Info("Recipe saved successfully")
 
@@ -294,6 +405,12 @@ func (h *Handler) ExportRecipe(c *gin.Context) {
 		return
 	}
 
+	system, ok := unitSystemParam(c)
+	if !ok {
+		return
+	}
+	convertRecipeData(&req.RecipeData, system)
+
 	timestamp := time.Now().Format("20060102_150405")
 
 	switch format {
@@ -368,6 +485,12 @@ func (h *Handler) ValidateIngredients(c *gin.Context) {
 }
 
 func (h *Handler) GetRecipes(c *gin.Context) {
+	h.listRecipes(c, func() *gorm.DB { return h.ownedRecipes(c) })
+}
+
+// listRecipes serves a recipe listing over the recipes returned by base, which
+// is called afresh for each query it needs.
+func (h *Handler) listRecipes(c *gin.Context, base func() *gorm.DB) {
 	page, exists := c.Get("page")
 	if !exists {
 		page = 1
@@ -376,61 +499,109 @@ func (h *Handler) GetRecipes(c *gin.Context) {
 	if !exists {
 		perPage = 10
 	}
-	search := c.Query("search")
-	minRating := c.Query("min_rating")
 
-	logrus.WithFields(logrus.Fields{
-		"search":     search,
-		"min_rating": minRating,
-		"page":       page,
-		"per_page":   perPage,
+	filters, err := parseRecipeFilters(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	search := filters.Search
+
+	sort, err := parseRecipeSort(c, search)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logrus.WithFields(filters.logFields()).WithFields(logrus.Fields{
+		"page":     page,
+		"per_page": perPage,
+		"sort":     sort.Name,
+		"order":    sort.Order,
 	}).Info("GetRecipes request parameters")
 
 	offset := (page.(int) - 1) * perPage.(int)
 
-	query := h.db.Model(&models.Recipe{})
+	filtered := func() *gorm.DB {
+		return filters.apply(base())
+	}
+	matching := func() *gorm.DB {
+		query := filtered()
+		if search != "" {
+			query = query.Where("search_vector @@ websearch_to_tsquery('english', ?)", search)
+		}
+		return query
+	}
 
 	if search != "" {
-		searchPattern := "%" + search + "%"
-		logrus.WithFields(logrus.Fields{
-			"search_term": search,
-			"pattern":     searchPattern,
-		}).Info("Executing search query")
-		
-		// Search in title, ingredients_used, or check if term appears before "Step-by-Step Instructions" section
-		query = query.Where("title ILIKE ? OR ingredients_used ILIKE ? OR (recipe_content ILIKE ? AND (POSITION(UPPER(?) IN UPPER(recipe_content)) < POSITION('STEP-BY-STEP INSTRUCTIONS' IN UPPER(recipe_content)) OR POSITION('STEP-BY-STEP INSTRUCTIONS' IN UPPER(recipe_content)) = 0))",
-			searchPattern, searchPattern, searchPattern, search)
+		logrus.WithField("search_term", search).Info("Executing full-text search query")
 	}
 
-	if minRating != "" {
-		query = query.Where("rating >= ?", minRating)
+	if cursor := c.Query("cursor"); cursor != "" || c.Query("pagination") == "cursor" {
+		h.listRecipesByCursor(c, matching, filters, sort, cursor, perPage.(int))
+		return
 	}
 
+	query := matching()
+
 	var total int64
 	query.Count(&total)
 
-	var recipes []models.Recipe
-	if err := query.Order("created_at DESC").Offset(offset).Limit(perPage.(int)).Find(&recipes).Error; err != nil {
+	query = selectListColumns(query, search)
+
+	var recipes []RecipeListItem
+	if err := query.Clauses(sort.orderBy(false)).Offset(offset).Limit(perPage.(int)).Find(&recipes).Error; err != nil {
 		logrus.WithError(err).Error("Failed to fetch recipes")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
 		return
 	}
 
+	response := gin.H{}
+	if search != "" && page.(int) == 1 && total < fuzzySearchMinResults {
+		fuzzyRecipes, err := h.fuzzySearch(filtered(), search, recipes, perPage.(int))
+		if err != nil {
+			logrus.WithError(err).Error("Failed to run fuzzy search")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+			return
+		}
+
+		if len(fuzzyRecipes) > len(recipes) {
+			recipes = fuzzyRecipes
+			total = int64(len(recipes))
+			response["fuzzy"] = true
+		}
+		if suggestion := h.searchSuggestion(search, base); suggestion != "" {
+			response["did_you_mean"] = suggestion
+		}
+	}
+
+	if filters.Facets {
+		facets, err := h.recipeFacets(matching)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to compute recipe facets")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+			return
+		}
+		response["facets"] = facets
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"search_term":   search,
 		"total_results": total,
 		"results_count": len(recipes),
+		"fuzzy":         response["fuzzy"] != nil,
 	}).Info("Search query completed")
 
 	pages := (int(total) + perPage.(int) - 1) / perPage.(int)
 
-	c.JSON(http.StatusOK, gin.H{
-		"recipes":      recipes,
-		"total":        total,
-		"pages":        pages,
-		"current_page": page,
-		"per_page":     perPage,
-	})
+	response["recipes"] = recipes
+	response["total"] = total
+	response["pages"] = pages
+	response["current_page"] = page
+	response["per_page"] = perPage
+	response["sort"] = sort.Name
+	response["order"] = sort.Order
+	c.JSON(http.StatusOK, response)
 }
 
 func (h *Handler) GetRecipe(c *gin.Context) {
@@ -440,8 +611,13 @@ func (h *Handler) GetRecipe(c *gin.Context) {
 		return
 	}
 
+	system, ok := unitSystemParam(c)
+	if !ok {
+		return
+	}
+
 	var recipe models.Recipe
-	if err := h.db.First(&recipe, id.(uint)).Error; err != nil {
+	if err := h.withStructure(h.ownedRecipes(c)).First(&recipe, id.(uint)).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
 		} else {
@@ -451,9 +627,12 @@ func (h *Handler) GetRecipe(c *gin.Context) {
 		return
 	}
 
+	convertRecipe(&recipe, system)
 	c.JSON(http.StatusOK, recipe)
 }
 
+// DeleteRecipe moves a recipe to the trash, where it can be restored until it
+// is purged.
 func (h *Handler) DeleteRecipe(c *gin.Context) {
 	id, exists := c.Get("id")
 	if !exists {
@@ -461,13 +640,23 @@ func (h *Handler) DeleteRecipe(c *gin.Context) {
 		return
 	}
 
-	if err := h.db.Delete(&models.Recipe{}, id.(uint)).Error; err != nil {
-		logrus.WithError(err).Error("Failed to delete recipe")
+	result := h.db.Where("user_id = ?", c.GetUint("user_id")).Delete(&models.Recipe{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete recipe")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recipe"})
 		return
 	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Recipe deleted successfully"})
+	logrus.WithFields(logrus.Fields{
+		"recipe_id": id.(uint),
+		"user_id":   c.GetUint("user_id"),
+	}).Info("Recipe moved to trash")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recipe moved to trash"})
 }
 
 func (h *Handler) UpdateRecipeRating(c *gin.Context) {
@@ -492,11 +681,16 @@ func (h *Handler) UpdateRecipeRating(c *gin.Context) {
 	}
 
 	// Update only the rating field
-	if err := h.db.Model(&models.Recipe{}).Where("id = ?", id.(uint)).Update("rating", req.Rating).Error; err != nil {
-		logrus.WithError(err).Error("Failed to update recipe rating")
+	result := h.ownedRecipes(c).Where("id = ?", id.(uint)).Update("rating", req.Rating)
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to update recipe rating")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe rating"})
 		return
 	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"recipe_id": id.(uint),
@@ -547,7 +741,7 @@ func (h *Handler) UpdateRecipe(c *gin.Context) {
 
 	// Get the existing recipe to check if it exists
 	var existingRecipe models.Recipe
-	if err := h.db.First(&existingRecipe, id.(uint)).Error; err != nil {
+	if err := h.ownedRecipes(c).First(&existingRecipe, id.(uint)).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
 		} else {
@@ -577,7 +771,13 @@ func (h *Handler) UpdateRecipe(c *gin.Context) {
 	newTitle := models.ExtractTitleFromContent(req.RecipeContent)
 	updates["title"] = newTitle
 
-	if err := h.db.Model(&existingRecipe).Updates(updates).Error; err != nil {
+	var revision *models.RecipeRevision
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		revision, err = applyRecipeUpdate(tx, existingRecipe.ID, updates, nil, c.GetUint("user_id"))
+		return err
+	})
+	if err != nil {
 		logrus.WithError(err).WithField("recipe_id", id.(uint)).Error("Failed to update recipe")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update recipe"})
 		return
@@ -586,72 +786,36 @@ func (h *Handler) UpdateRecipe(c *gin.Context) {
 	logrus.WithFields(logrus.Fields{
 		"recipe_id": id.(uint),
 		"title":     newTitle,
+		"revision":  revision.Revision,
 		"ip":        c.ClientIP(),
 	}).Info("Recipe updated successfully")
 
 	// Return the updated recipe
 	var updatedRecipe models.Recipe
-	if err := h.db.First(&updatedRecipe, id.(uint)).Error; err != nil {
+	if err := h.withStructure(h.db).First(&updatedRecipe, id.(uint)).Error; err != nil {
 		logrus.WithError(err).Error("Failed to fetch updated recipe")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch updated recipe"})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Recipe updated successfully",
-		"recipe":  updatedRecipe,
+		"message":  "Recipe updated successfully",
+		"recipe":   updatedRecipe,
+		"revision": revision.Revision,
 	})
 }
 
-func (h *Handler) callAnthropicAPI(prompt string) (string, error) {
-	reqBody := AnthropicRequest{
-		Model:       h.cfg.ClaudeModel,
-		MaxTokens:   2000,
-		Temperature: 0.7,
-		Messages: []AnthropicMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequest("POST", "https://api.anthropic.com/v1/messages", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", h.cfg.AnthropicAPIKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
-
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("failed to make request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
-	}
-
-	var anthropicResp AnthropicResponse
-	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
-		return "", fmt.Errorf("failed to decode response: %w", err)
-	}
+func (h *Handler) withStructure(db *gorm.DB) *gorm.DB {
+	return db.Preload("Ingredients", models.OrderByPosition).Preload("Steps", models.OrderByPosition).Preload("Tags", models.OrderByName)
+}
 
-	if len(anthropicResp.Content) == 0 {
-		return "", fmt.Errorf("no content in response")
+// recipeStructure prefers the model's structured output and only falls back to
+// parsing the prose when the recipe was produced without it.
+func recipeStructure(data RecipeData) ([]structured.Ingredient, []string) {
+	if data.Structured != nil {
+		return data.Structured.Ingredients, data.Structured.Steps
 	}
-
-	return anthropicResp.Content[0].Text, nil
+	return recipeparse.ParseContent(data.Recipe)
 }
 
 func getStringValue(value, defaultValue string) string {
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/recipeparse"
+	"recipe-ai/internal/shopping"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxMatchIngredients  = 50
+	maxRecipeMatches     = 50
+	defaultRecipeMatches = 20
+)
+
+// RecipeMatch is a saved recipe scored against the ingredients at hand.
+// Coverage is the percentage of its non-staple ingredients that are present.
+type RecipeMatch struct {
+	ID       uint     `json:"id"`
+	Title    string   `json:"title"`
+	Rating   *int     `json:"rating"`
+	Coverage int      `json:"coverage"`
+	Matched  []string `json:"matched"`
+	Missing  []string `json:"missing"`
+}
+
+// MatchRecipes ranks the caller's saved recipes by how many of their
+// ingredients are in have, best first, so a suitable recipe can be found
+// before generating a new one. Staples such as salt and oil are assumed to be
+// on hand. Recipes using none of the ingredients are left out.
+func (h *Handler) MatchRecipes(c *gin.Context) {
+	have := multiValueQuery(c, "have")
+	if len(have) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please list your ingredients, e.g. have=chicken,rice"})
+		return
+	}
+	if len(have) > maxMatchIngredients {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("have can list at most %d ingredients", maxMatchIngredients)})
+		return
+	}
+
+	minCoverage, err := intQuery(c, "min_coverage", 0, 100)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	limit, err := intQuery(c, "limit", 1, maxRecipeMatches)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if limit == 0 {
+		limit = defaultRecipeMatches
+	}
+
+	var recipes []models.Recipe
+	err = h.ownedRecipes(c).
+		Select("recipes.id, recipes.title, recipes.rating, recipes.recipe_content").
+		Preload("Ingredients", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Find(&recipes).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipes for matching")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to match recipes"})
+		return
+	}
+
+	matches := []RecipeMatch{}
+	for _, recipe := range recipes {
+		match, ok := matchRecipe(recipe, have, h.cfg.PantryStaples)
+		if ok && match.Coverage >= minCoverage {
+			matches = append(matches, match)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		a, b := matches[i], matches[j]
+		if a.Coverage != b.Coverage {
+			return a.Coverage > b.Coverage
+		}
+		if len(a.Missing) != len(b.Missing) {
+			return len(a.Missing) < len(b.Missing)
+		}
+		return a.Title < b.Title
+	})
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"have":    have,
+		"staples": h.cfg.PantryStaples,
+		"matches": matches,
+	})
+}
+
+// matchRecipe scores a recipe against the ingredients at hand, reporting false
+// when it uses none of them.
+func matchRecipe(recipe models.Recipe, have, staples []string) (RecipeMatch, bool) {
+	// Recipes saved before ingredients were normalized are parsed on the fly.
+	rows := recipe.Ingredients
+	if len(rows) == 0 {
+		parsed, _ := recipeparse.ParseContent(recipe.RecipeContent)
+		rows = models.NewRecipeIngredients(parsed)
+	}
+
+	match := RecipeMatch{
+		ID:      recipe.ID,
+		Title:   recipe.Title,
+		Rating:  recipe.Rating,
+		Matched: []string{},
+		Missing: []string{},
+	}
+	for _, row := range rows {
+		switch {
+		case shopping.Includes(staples, row.Item):
+		case shopping.Have(have, row.Item):
+			match.Matched = append(match.Matched, row.Item)
+		default:
+			match.Missing = append(match.Missing, row.Item)
+		}
+	}
+
+	if len(match.Matched) == 0 {
+		return match, false
+	}
+	total := len(match.Matched) + len(match.Missing)
+	match.Coverage = (len(match.Matched)*100 + total/2) / total
+	return match, true
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// maxMealPlanDays bounds the range GetMealPlan returns, enough for a month
+// view padded out to whole weeks.
+const maxMealPlanDays = 42
+
+// MealPlanEntryRequest creates or updates a meal plan entry. On update,
+// omitted fields are left unchanged and servings of 0 removes the override.
+type MealPlanEntryRequest struct {
+	RecipeID *uint        `json:"recipe_id"`
+	Date     *models.Date `json:"date"`
+	Slot     *string      `json:"slot"`
+	Servings *int         `json:"servings"`
+	Note     *string      `json:"note"`
+}
+
+// MealPlanEntryResponse adds the servings to cook, which is the override or
+// else the recipe's own serving size.
+type MealPlanEntryResponse struct {
+	models.MealPlanEntry
+	Servings int `json:"servings"`
+}
+
+type MealPlanDay struct {
+	Date    models.Date             `json:"date"`
+	Entries []MealPlanEntryResponse `json:"entries"`
+}
+
+// GetMealPlan returns the caller's meal plan for a range of days, with every
+// day listed even when nothing is planned. Pass from and to, or view=week
+// (the default, Monday to Sunday) or view=month around date, which defaults
+// to today.
+func (h *Handler) GetMealPlan(c *gin.Context) {
+	from, to, err := mealPlanRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entries, err := h.mealPlanEntries(c, from, to)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch meal plan")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan"})
+		return
+	}
+
+	days := []MealPlanDay{}
+	index := map[string]int{}
+	for day := from; !day.After(to.Time); day = day.AddDays(1) {
+		index[day.String()] = len(days)
+		days = append(days, MealPlanDay{Date: day, Entries: []MealPlanEntryResponse{}})
+	}
+	for _, entry := range entries {
+		if i, ok := index[entry.Date.String()]; ok {
+			days[i].Entries = append(days[i].Entries, newMealPlanEntryResponse(entry))
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": from,
+		"to":   to,
+		"days": days,
+	})
+}
+
+func (h *Handler) GetMealPlanEntry(c *gin.Context) {
+	entry, ok := h.ownedMealPlanEntry(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entry": newMealPlanEntryResponse(*entry)})
+}
+
+func (h *Handler) CreateMealPlanEntry(c *gin.Context) {
+	var req MealPlanEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+	if req.RecipeID == nil || req.Date == nil || req.Slot == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "recipe_id, date and slot are required"})
+		return
+	}
+
+	entry := models.MealPlanEntry{UserID: c.GetUint("user_id")}
+	if !h.applyMealPlanRequest(c, &entry, req) {
+		return
+	}
+
+	if err := h.db.Create(&entry).Error; err != nil {
+		logrus.WithError(err).Error("Failed to create meal plan entry")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add to meal plan"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"entry_id":  entry.ID,
+		"recipe_id": entry.RecipeID,
+		"date":      entry.Date.String(),
+		"slot":      entry.Slot,
+		"user_id":   entry.UserID,
+	}).Info("Meal plan entry created")
+
+	h.respondMealPlanEntry(c, http.StatusCreated, entry.ID)
+}
+
+func (h *Handler) UpdateMealPlanEntry(c *gin.Context) {
+	entry, ok := h.ownedMealPlanEntry(c)
+	if !ok {
+		return
+	}
+
+	var req MealPlanEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+	if !h.applyMealPlanRequest(c, entry, req) {
+		return
+	}
+
+	err := h.db.Model(entry).
+		Select("recipe_id", "date", "slot", "servings_override", "note", "updated_at").
+		Updates(entry).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update meal plan entry")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update meal plan entry"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"entry_id": entry.ID,
+		"user_id":  entry.UserID,
+	}).Info("Meal plan entry updated")
+
+	h.respondMealPlanEntry(c, http.StatusOK, entry.ID)
+}
+
+func (h *Handler) DeleteMealPlanEntry(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meal plan entry ID"})
+		return
+	}
+
+	result := h.db.Where("user_id = ?", c.GetUint("user_id")).Delete(&models.MealPlanEntry{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete meal plan entry")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete meal plan entry"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Meal plan entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Meal plan entry deleted successfully"})
+}
+
+// mealPlanEntries loads the caller's entries between from and to inclusive,
+// with their recipes, in calendar order. Entries for trashed recipes are left
+// out.
+func (h *Handler) mealPlanEntries(c *gin.Context, from, to models.Date) ([]models.MealPlanEntry, error) {
+	var entries []models.MealPlanEntry
+	err := h.db.Preload("Recipe").
+		Where("user_id = ? AND date BETWEEN ? AND ?", c.GetUint("user_id"), from, to).
+		Where("recipe_id IN (?)", h.db.Model(&models.Recipe{}).Select("id")).
+		Scopes(models.OrderBySlot).
+		Find(&entries).Error
+	return entries, err
+}
+
+// ownedMealPlanEntry loads the :id entry if it belongs to the caller, writing
+// a 404 response and returning false when it does not.
+func (h *Handler) ownedMealPlanEntry(c *gin.Context) (*models.MealPlanEntry, bool) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid meal plan entry ID"})
+		return nil, false
+	}
+
+	var entry models.MealPlanEntry
+	if err := h.db.Preload("Recipe").Where("user_id = ?", c.GetUint("user_id")).First(&entry, id.(uint)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Meal plan entry not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch meal plan entry")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan entry"})
+		}
+		return nil, false
+	}
+	return &entry, true
+}
+
+// applyMealPlanRequest validates req and copies the fields it sets onto
+// entry, writing an error response and returning false when invalid.
+func (h *Handler) applyMealPlanRequest(c *gin.Context, entry *models.MealPlanEntry, req MealPlanEntryRequest) bool {
+	if req.Slot != nil {
+		slot := strings.ToLower(strings.TrimSpace(*req.Slot))
+		if !slices.Contains(models.MealSlots, slot) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "slot must be one of " + strings.Join(models.MealSlots, ", ")})
+			return false
+		}
+		entry.Slot = slot
+	}
+
+	if req.Servings != nil {
+		switch {
+		case *req.Servings == 0:
+			entry.ServingsOverride = nil
+		case *req.Servings < 1 || *req.Servings > maxScaledServings:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Servings must be a whole number between 1 and 100"})
+			return false
+		default:
+			entry.ServingsOverride = req.Servings
+		}
+	}
+
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		if len(note) > 200 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Note cannot be longer than 200 characters"})
+			return false
+		}
+		entry.Note = note
+	}
+
+	if req.Date != nil {
+		entry.Date = *req.Date
+	}
+
+	if req.RecipeID != nil {
+		if !h.checkOwnedRecipe(c, *req.RecipeID) {
+			return false
+		}
+		entry.RecipeID = *req.RecipeID
+		entry.Recipe = nil
+	}
+
+	return true
+}
+
+func (h *Handler) respondMealPlanEntry(c *gin.Context, status int, id uint) {
+	var entry models.MealPlanEntry
+	if err := h.db.Preload("Recipe").First(&entry, id).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch meal plan entry")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan entry"})
+		return
+	}
+
+	c.JSON(status, gin.H{"entry": newMealPlanEntryResponse(entry)})
+}
+
+func newMealPlanEntryResponse(entry models.MealPlanEntry) MealPlanEntryResponse {
+	return MealPlanEntryResponse{MealPlanEntry: entry, Servings: entry.EffectiveServings()}
+}
+
+// mealPlanRange reads the from/to or view/date query parameters of a meal
+// plan request.
+func mealPlanRange(c *gin.Context) (models.Date, models.Date, error) {
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, err := models.ParseDate(c.Query("from"))
+		if err != nil {
+			return from, from, fmt.Errorf("from: %w", err)
+		}
+		to, err := models.ParseDate(c.Query("to"))
+		if err != nil {
+			return from, to, fmt.Errorf("to: %w", err)
+		}
+		if to.Before(from.Time) {
+			return from, to, fmt.Errorf("from cannot be after to")
+		}
+		if to.Sub(from.Time) >= maxMealPlanDays*24*time.Hour {
+			return from, to, fmt.Errorf("a meal plan range can cover at most %d days", maxMealPlanDays)
+		}
+		return from, to, nil
+	}
+
+	anchor := models.Today()
+	if raw := c.Query("date"); raw != "" {
+		var err error
+		if anchor, err = models.ParseDate(raw); err != nil {
+			return anchor, anchor, err
+		}
+	}
+
+	switch c.DefaultQuery("view", "week") {
+	case "week":
+		// Weeks start on Monday.
+		from := anchor.AddDays(-((int(anchor.Weekday()) + 6) % 7))
+		return from, from.AddDays(6), nil
+	case "month":
+		from := models.NewDate(anchor.Year(), anchor.Month(), 1)
+		return from, models.Date{Time: from.AddDate(0, 1, -1)}, nil
+	default:
+		return anchor, anchor, fmt.Errorf("view must be week or month")
+	}
+}
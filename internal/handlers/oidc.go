@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"recipe-ai/internal/auth"
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	errOIDCNoEmail       = errors.New("your identity provider did not share an email address")
+	errOIDCUnverified    = errors.New("your identity provider has not verified your email address")
+	errOIDCEmailConflict = errors.New("an account with this email already exists; sign in with your password instead")
+)
+
+func (h *Handler) OIDCLogin(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	url, err := h.oidc.AuthCodeURL(c.Request.Context(), c.Writer)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to start OIDC sign-in")
+		h.loginError(c, http.StatusBadGateway, "Single sign-on is unavailable, please try again later")
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+func (h *Handler) OIDCCallback(c *gin.Context) {
+	if h.oidc == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Single sign-on is not configured"})
+		return
+	}
+
+	claims, err := h.oidc.Exchange(c.Request.Context(), c.Request, c.Writer)
+	if err != nil {
+		if errors.Is(err, auth.ErrOIDCState) {
+			h.loginError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		logrus.WithError(err).WithField("ip", c.ClientIP()).Warn("OIDC sign-in failed")
+		h.loginError(c, http.StatusUnauthorized, "Single sign-on failed, please try again")
+		return
+	}
+
+	user, err := h.oidcUser(claims)
+	if err != nil {
+		if errors.Is(err, errOIDCNoEmail) || errors.Is(err, errOIDCUnverified) || errors.Is(err, errOIDCEmailConflict) {
+			h.loginError(c, http.StatusConflict, err.Error())
+		} else {
+			logrus.WithError(err).Error("Failed to resolve OIDC account")
+			h.loginError(c, http.StatusInternalServerError, "Failed to sign in")
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"user_id": user.ID,
+		"issuer":  claims.Issuer,
+		"ip":      c.ClientIP(),
+	}).Info("User logged in with OIDC")
+
+	h.sessions.Issue(c.Writer, user.ID)
+	c.Redirect(http.StatusFound, "/")
+}
+
+// oidcUser maps verified claims to a local account: by issuer and subject,
+// then by verified email for existing accounts signing in with SSO for the
+// first time, and otherwise by creating a new password-less account. Both of
+// the latter require a verified email: an unverified one would let anyone who
+// controls their identity provider profile claim another person's address.
+func (h *Handler) oidcUser(claims *auth.OIDCClaims) (*models.User, error) {
+	var user models.User
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("oidc_issuer = ? AND oidc_subject = ?", claims.Issuer, claims.Subject).First(&user).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		email, ok := normalizeEmail(claims.Email)
+		if !ok {
+			return errOIDCNoEmail
+		}
+
+		if !claims.EmailVerified {
+			return errOIDCUnverified
+		}
+
+		err = tx.Where("email = ?", email).First(&user).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			user = models.User{
+				Email:       email,
+				Role:        string(auth.DefaultRole),
+				OIDCIssuer:  &claims.Issuer,
+				OIDCSubject: &claims.Subject,
+			}
+			return tx.Create(&user).Error
+		case err != nil:
+			return err
+		case user.OIDCSubject != nil:
+			return errOIDCEmailConflict
+		}
+
+		user.OIDCIssuer = &claims.Issuer
+		user.OIDCSubject = &claims.Subject
+		return tx.Model(&user).Updates(map[string]interface{}{
+			"oidc_issuer":  claims.Issuer,
+			"oidc_subject": claims.Subject,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (h *Handler) loginError(c *gin.Context, status int, message string) {
+	c.HTML(status, "login.html", gin.H{"OIDC": h.oidc != nil, "Error": message})
+}
@@ -0,0 +1,324 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/shopping"
+	"recipe-ai/internal/structured"
+	"recipe-ai/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxPantryItemLength = 200
+	// maxPantryPromptItems bounds how many pantry items, soonest expiring
+	// first, are offered to the model when cooking from the pantry.
+	maxPantryPromptItems = 50
+)
+
+// PantryItemRequest creates or updates a pantry item. On update, omitted
+// fields are left unchanged and an empty expires_on removes the expiry date.
+type PantryItemRequest struct {
+	Item      *string `json:"item"`
+	Quantity  *string `json:"quantity"`
+	Unit      *string `json:"unit"`
+	ExpiresOn *string `json:"expires_on"`
+}
+
+// PantryItemResponse adds the days left before an item expires.
+type PantryItemResponse struct {
+	models.PantryItem
+	DaysLeft *int `json:"days_left"`
+}
+
+// PantryUsage reports, for a recipe generated from the pantry, which pantry
+// items it uses and which of its ingredients would need to be bought.
+type PantryUsage struct {
+	Used  []string                `json:"used"`
+	ToBuy []structured.Ingredient `json:"to_buy"`
+}
+
+// GetPantry lists the caller's pantry, soonest expiring first. Pass
+// expiring_within=N to list only items expiring in the next N days, including
+// ones already past their date.
+func (h *Handler) GetPantry(c *gin.Context) {
+	within, err := intQuery(c, "expiring_within", 0, 365)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	today := models.Today()
+	query := h.db.Where("user_id = ?", c.GetUint("user_id")).Scopes(models.OrderByExpiry)
+	if c.Query("expiring_within") != "" {
+		query = query.Where("expires_on <= ?", today.AddDays(within))
+	}
+
+	var items []models.PantryItem
+	if err := query.Find(&items).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch pantry")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pantry"})
+		return
+	}
+
+	response := make([]PantryItemResponse, 0, len(items))
+	for _, item := range items {
+		response = append(response, PantryItemResponse{PantryItem: item, DaysLeft: item.DaysLeft(today)})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": response})
+}
+
+func (h *Handler) GetPantryItem(c *gin.Context) {
+	item, ok := h.ownedPantryItem(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": newPantryItemResponse(*item)})
+}
+
+func (h *Handler) CreatePantryItem(c *gin.Context) {
+	var req PantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	if req.Item == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "item is required"})
+		return
+	}
+
+	item := models.PantryItem{UserID: c.GetUint("user_id")}
+	if !applyPantryRequest(c, &item, req) {
+		return
+	}
+
+	if err := h.db.Create(&item).Error; err != nil {
+		logrus.WithError(err).Error("Failed to create pantry item")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add pantry item"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pantry_item_id": item.ID,
+		"user_id":        item.UserID,
+	}).Info("Pantry item created")
+
+	c.JSON(http.StatusCreated, gin.H{"item": newPantryItemResponse(item)})
+}
+
+func (h *Handler) UpdatePantryItem(c *gin.Context) {
+	item, ok := h.ownedPantryItem(c)
+	if !ok {
+		return
+	}
+
+	var req PantryItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+	if !applyPantryRequest(c, item, req) {
+		return
+	}
+
+	err := h.db.Model(item).
+		Select("item", "quantity", "unit", "expires_on", "updated_at").
+		Updates(item).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update pantry item")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update pantry item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": newPantryItemResponse(*item)})
+}
+
+func (h *Handler) DeletePantryItem(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pantry item ID"})
+		return
+	}
+
+	result := h.db.Where("user_id = ?", c.GetUint("user_id")).Delete(&models.PantryItem{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete pantry item")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete pantry item"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Pantry item not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pantry item deleted successfully"})
+}
+
+// ownedPantryItem loads the :id pantry item if it belongs to the caller,
+// writing a 404 response and returning false when it does not.
+func (h *Handler) ownedPantryItem(c *gin.Context) (*models.PantryItem, bool) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pantry item ID"})
+		return nil, false
+	}
+
+	var item models.PantryItem
+	if err := h.db.Where("user_id = ?", c.GetUint("user_id")).First(&item, id.(uint)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Pantry item not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch pantry item")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pantry item"})
+		}
+		return nil, false
+	}
+	return &item, true
+}
+
+// applyPantryRequest validates req and copies the fields it sets onto item,
+// writing an error response and returning false when invalid.
+func applyPantryRequest(c *gin.Context, item *models.PantryItem, req PantryItemRequest) bool {
+	if req.Item != nil {
+		name := strings.TrimSpace(*req.Item)
+		if name == "" || len(name) > maxPantryItemLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "item must be between 1 and 200 characters"})
+			return false
+		}
+		item.Item = name
+	}
+
+	if req.Quantity != nil {
+		quantity := strings.TrimSpace(*req.Quantity)
+		if quantity != "" {
+			if _, err := units.ParseQuantity(quantity); err != nil || len(quantity) > 50 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid quantity %q", quantity)})
+				return false
+			}
+		}
+		item.Quantity = quantity
+	}
+
+	if req.Unit != nil {
+		unit := strings.TrimSpace(*req.Unit)
+		if canonical, ok := units.Canonical(unit); ok {
+			unit = canonical
+		}
+		if len(unit) > 50 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unit cannot be longer than 50 characters"})
+			return false
+		}
+		item.Unit = unit
+	}
+
+	if req.ExpiresOn != nil {
+		if *req.ExpiresOn == "" {
+			item.ExpiresOn = nil
+		} else {
+			date, err := models.ParseDate(*req.ExpiresOn)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "expires_on: " + err.Error()})
+				return false
+			}
+			item.ExpiresOn = &date
+		}
+	}
+
+	return true
+}
+
+func newPantryItemResponse(item models.PantryItem) PantryItemResponse {
+	return PantryItemResponse{PantryItem: item, DaysLeft: item.DaysLeft(models.Today())}
+}
+
+// pantryForRecipe loads the pantry items to cook with, soonest expiring first,
+// leaving out anything already past its date.
+func (h *Handler) pantryForRecipe(userID uint) ([]models.PantryItem, error) {
+	var items []models.PantryItem
+	err := h.db.Where("user_id = ?", userID).
+		Where("expires_on IS NULL OR expires_on >= ?", models.Today()).
+		Scopes(models.OrderByExpiry).
+		Limit(maxPantryPromptItems).
+		Find(&items).Error
+	return items, err
+}
+
+// recipePantry loads the pantry for a request with use_pantry set, filling in
+// its ingredients from the pantry when none were listed. It writes an error
+// response and returns false when the pantry cannot be used.
+func (h *Handler) recipePantry(c *gin.Context, req *RecipeRequest) ([]models.PantryItem, bool) {
+	if !req.UsePantry {
+		return nil, true
+	}
+
+	pantry, err := h.pantryForRecipe(c.GetUint("user_id"))
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch pantry")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch pantry"})
+		return nil, false
+	}
+	if len(pantry) == 0 && req.Ingredients == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Your pantry is empty; add pantry items or list some ingredients"})
+		return nil, false
+	}
+	if req.Ingredients == "" {
+		names := make([]string, 0, len(pantry))
+		for _, item := range pantry {
+			names = append(names, item.Item)
+		}
+		req.Ingredients = strings.Join(names, ", ")
+	}
+	return pantry, true
+}
+
+// buildPantryPrompt adds the pantry to a recipe prompt, asking the model to
+// use up the items that expire soonest.
+func buildPantryPrompt(prompt string, items []models.PantryItem) string {
+	var text strings.Builder
+	text.WriteString(prompt)
+	text.WriteString("\n\nCook mainly from this pantry, listed soonest expiring first. Use the items near the top whenever they suit the dish, and add as few other ingredients as possible:\n")
+	for _, item := range items {
+		text.WriteString("- " + shopping.Line(item.Quantity, item.Unit, item.Item))
+		if item.ExpiresOn != nil {
+			text.WriteString(" (expires " + item.ExpiresOn.String() + ")")
+		}
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
+// pantryUsage compares a generated recipe's ingredients with what the caller
+// has: the pantry and anything they listed in the request.
+func pantryUsage(recipe *structured.Recipe, items []models.PantryItem, listed string) *PantryUsage {
+	usage := &PantryUsage{Used: []string{}, ToBuy: []structured.Ingredient{}}
+
+	onHand := strings.Split(listed, ",")
+	for _, item := range items {
+		onHand = append(onHand, item.Item)
+	}
+
+	for _, item := range items {
+		for _, ingredient := range recipe.Ingredients {
+			if shopping.Have([]string{item.Item}, ingredient.Item) && !slices.Contains(usage.Used, item.Item) {
+				usage.Used = append(usage.Used, item.Item)
+				break
+			}
+		}
+	}
+	for _, ingredient := range recipe.Ingredients {
+		if !shopping.Have(onHand, ingredient.Item) {
+			usage.ToBuy = append(usage.ToBuy, ingredient)
+		}
+	}
+	return usage
+}
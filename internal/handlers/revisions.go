@@ -0,0 +1,270 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/structured"
+	"recipe-ai/internal/textdiff"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// RecipeRevisionSummary is a revision as listed by GetRecipeRevisions, without
+// its content.
+type RecipeRevisionSummary struct {
+	Revision    int       `json:"revision"`
+	Title       string    `json:"title"`
+	ServingSize int       `json:"serving_size"`
+	AuthorID    *uint     `json:"author_id"`
+	CreatedAt   time.Time `json:"timestamp"`
+}
+
+type FieldChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+func (h *Handler) GetRecipeRevisions(c *gin.Context) {
+	recipeID, ok := h.ownedRecipeID(c)
+	if !ok {
+		return
+	}
+
+	revisions := []RecipeRevisionSummary{}
+	err := h.db.Model(&models.RecipeRevision{}).
+		Where("recipe_id = ?", recipeID).
+		Order("revision DESC").
+		Scan(&revisions).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipe revisions")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+func (h *Handler) GetRecipeRevision(c *gin.Context) {
+	recipeID, ok := h.ownedRecipeID(c)
+	if !ok {
+		return
+	}
+
+	revision, ok := h.revisionParam(c, recipeID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+// DiffRecipeRevisions compares the content of two revisions line by line. from
+// defaults to the revision before to, and to to the latest revision. Pass
+// format=unified for a plain-text unified diff.
+func (h *Handler) DiffRecipeRevisions(c *gin.Context) {
+	recipeID, ok := h.ownedRecipeID(c)
+	if !ok {
+		return
+	}
+
+	toParam := c.Query("to")
+	if toParam == "" {
+		var latest int
+		err := h.db.Model(&models.RecipeRevision{}).Where("recipe_id = ?", recipeID).
+			Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error
+		if err != nil {
+			logrus.WithError(err).Error("Failed to fetch latest revision")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+			return
+		}
+		toParam = strconv.Itoa(latest)
+	}
+
+	to, ok := h.revisionParam(c, recipeID, toParam)
+	if !ok {
+		return
+	}
+
+	fromParam := c.Query("from")
+	if fromParam == "" {
+		fromParam = strconv.Itoa(max(to.Revision-1, 1))
+	}
+	from, ok := h.revisionParam(c, recipeID, fromParam)
+	if !ok {
+		return
+	}
+
+	lines := textdiff.Lines(from.RecipeContent, to.RecipeContent)
+
+	if c.Query("format") == "unified" {
+		diff := textdiff.Unified(lines, fmt.Sprintf("revision %d", from.Revision), fmt.Sprintf("revision %d", to.Revision), 3)
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(diff))
+		return
+	}
+
+	inserted, deleted := textdiff.Stats(lines)
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from.Revision,
+		"to":      to.Revision,
+		"lines":   lines,
+		"added":   inserted,
+		"removed": deleted,
+		"changes": revisionFieldChanges(from, to),
+	})
+}
+
+// RestoreRecipeRevision makes an old revision current again. The restore is
+// itself recorded as a new revision, so no history is lost.
+func (h *Handler) RestoreRecipeRevision(c *gin.Context) {
+	recipeID, ok := h.ownedRecipeID(c)
+	if !ok {
+		return
+	}
+
+	source, ok := h.revisionParam(c, recipeID, c.Param("rev"))
+	if !ok {
+		return
+	}
+
+	updates := map[string]interface{}{
+		"title":                source.Title,
+		"recipe_content":       source.RecipeContent,
+		"ingredients_used":     source.IngredientsUsed,
+		"dietary_restrictions": source.DietaryRestrictions,
+		"cuisine_preference":   source.CuisinePreference,
+		"serving_size":         source.ServingSize,
+		"updated_at":           time.Now(),
+	}
+
+	var revision *models.RecipeRevision
+	err := h.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		revision, err = applyRecipeUpdate(tx, recipeID, updates, nil, c.GetUint("user_id"))
+		return err
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("recipe_id", recipeID).Error("Failed to restore recipe revision")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore revision"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"recipe_id":     recipeID,
+		"restored_from": source.Revision,
+		"revision":      revision.Revision,
+		"user_id":       c.GetUint("user_id"),
+	}).Info("Recipe revision restored")
+
+	var recipe models.Recipe
+	if err := h.withStructure(h.db).First(&recipe, recipeID).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch restored recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch restored recipe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       fmt.Sprintf("Restored revision %d", source.Revision),
+		"recipe":        recipe,
+		"revision":      revision.Revision,
+		"restored_from": source.Revision,
+	})
+}
+
+// applyRecipeUpdate writes updates, which must include recipe_content, to a
+// recipe, replaces its ingredients and steps with those of recipe, or with
+// ones parsed from the new content when recipe is nil, and records the result
+// as a new revision. Call it inside a transaction.
+func applyRecipeUpdate(tx *gorm.DB, recipeID uint, updates map[string]interface{}, recipe *structured.Recipe, authorID uint) (*models.RecipeRevision, error) {
+	if err := tx.Model(&models.Recipe{}).Where("id = ?", recipeID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	ingredients, steps := recipeStructure(RecipeData{Recipe: updates["recipe_content"].(string), Structured: recipe})
+	if err := models.ReplaceRecipeStructure(tx, recipeID, ingredients, steps); err != nil {
+		return nil, err
+	}
+
+	return models.RecordRevision(tx, recipeID, &authorID)
+}
+
+// ownedRecipeID checks that the :id recipe belongs to the caller, writing a
+// 404 response and returning false when it does not.
+func (h *Handler) ownedRecipeID(c *gin.Context) (uint, bool) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return 0, false
+	}
+
+	var count int64
+	if err := h.ownedRecipes(c).Where("id = ?", id.(uint)).Count(&count).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe"})
+		return 0, false
+	}
+	if count == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		return 0, false
+	}
+
+	return id.(uint), true
+}
+
+// revisionParam loads a revision of a recipe by its number, writing a 400 or
+// 404 response and returning false when it is invalid or missing.
+func (h *Handler) revisionParam(c *gin.Context, recipeID uint, raw string) (*models.RecipeRevision, bool) {
+	number, err := strconv.Atoi(raw)
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Revision must be a positive whole number"})
+		return nil, false
+	}
+
+	var revision models.RecipeRevision
+	if err := h.db.Where("recipe_id = ? AND revision = ?", recipeID, number).First(&revision).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Revision %d not found", number)})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch recipe revision")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revision"})
+		}
+		return nil, false
+	}
+
+	return &revision, true
+}
+
+// revisionFieldChanges lists the fields other than the content that differ
+// between two revisions.
+func revisionFieldChanges(from, to *models.RecipeRevision) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	if from.Title != to.Title {
+		changes["title"] = FieldChange{from.Title, to.Title}
+	}
+	if from.IngredientsUsed != to.IngredientsUsed {
+		changes["ingredients_used"] = FieldChange{from.IngredientsUsed, to.IngredientsUsed}
+	}
+	if from.ServingSize != to.ServingSize {
+		changes["serving_size"] = FieldChange{from.ServingSize, to.ServingSize}
+	}
+	if deref(from.DietaryRestrictions) != deref(to.DietaryRestrictions) {
+		changes["dietary_restrictions"] = FieldChange{from.DietaryRestrictions, to.DietaryRestrictions}
+	}
+	if deref(from.CuisinePreference) != deref(to.CuisinePreference) {
+		changes["cuisine_preference"] = FieldChange{from.CuisinePreference, to.CuisinePreference}
+	}
+	return changes
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
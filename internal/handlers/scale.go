@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/recipeparse"
+	"recipe-ai/internal/units"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const maxScaledServings = 100
+
+// servingsLinePattern finds the line of recipe text that states the number
+// of servings, such as "Servings: 4" or "**Serves:** 4".
+var servingsLinePattern = regexp.MustCompile(`(?im)^([\s*#-]*(?:servings|serving size|serves|yield)\b[^0-9\n]*)\d+`)
+
+func (h *Handler) GetScaledRecipe(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	servings, err := strconv.Atoi(c.Query("servings"))
+	if err != nil || servings < 1 || servings > maxScaledServings {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Servings must be a whole number between 1 and 100"})
+		return
+	}
+
+	var recipe models.Recipe
+	if err := h.withStructure(h.ownedRecipes(c)).First(&recipe, id.(uint)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch recipe for scaling")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipe"})
+		}
+		return
+	}
+
+	// Recipes saved before ingredients were normalized are parsed on the fly.
+	if len(recipe.Ingredients) == 0 {
+		ingredients, _ := recipeparse.ParseContent(recipe.RecipeContent)
+		recipe.Ingredients = models.NewRecipeIngredients(ingredients)
+	}
+
+	originalServings := recipe.ServingSize
+	if originalServings <= 0 {
+		originalServings = 4
+	}
+	factor := float64(servings) / float64(originalServings)
+
+	scaledCount := 0
+	for i := range recipe.Ingredients {
+		ingredient := &recipe.Ingredients[i]
+		quantity, unit, ok := units.ScaleText(ingredient.Quantity, ingredient.Unit, factor)
+		if ok {
+			ingredient.Quantity = quantity
+			ingredient.Unit = unit
+			scaledCount++
+		}
+	}
+	recipe.ServingSize = servings
+	// Rewrite the text to match, so it does not contradict the scaled rows.
+	content := recipeparse.RewriteIngredientLines(recipe.RecipeContent, func(line string) string {
+		return units.ScaleLine(line, factor)
+	})
+	recipe.RecipeContent = servingsLinePattern.ReplaceAllString(content, "${1}"+strconv.Itoa(servings))
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipe":             recipe,
+		"original_servings":  originalServings,
+		"servings":           servings,
+		"factor":             factor,
+		"scaled_ingredients": scaledCount,
+	})
+}
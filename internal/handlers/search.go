@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"regexp"
+	"strings"
+
+	"recipe-ai/internal/models"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// fuzzySearchMinResults is the number of full-text matches below which the
+// trigram fallback runs.
+const fuzzySearchMinResults = 3
+
+// searchRankColumns ranks matches by the weighted search_vector and builds a
+// short snippet of the recipe content with matched terms wrapped in <mark>.
+const searchRankColumns = `ts_rank(search_vector, websearch_to_tsquery('english', ?)) AS rank,
+	ts_headline('english', recipe_content, websearch_to_tsquery('english', ?),
+		'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MaxWords=20, MinWords=5') AS headline`
+
+// selectListColumns selects the recipe columns plus, for searches, rank and
+// headline, and loads each recipe's tags. The explicit select matters:
+// scanning into RecipeListItem would otherwise make GORM ask for its read-only
+// columns by name.
+func selectListColumns(query *gorm.DB, search string) *gorm.DB {
+	query = query.Preload("Tags", models.OrderByName)
+	if search == "" {
+		return query.Select("recipes.*")
+	}
+	return query.Select("recipes.*, "+searchRankColumns, search, search)
+}
+
+var searchTermPattern = regexp.MustCompile(`[^\pL]+`)
+
+// fuzzySearch finds recipes whose title or ingredients are similar to the
+// search text, keeping the full-text matches first.
+func (h *Handler) fuzzySearch(query *gorm.DB, search string, matches []RecipeListItem, limit int) ([]RecipeListItem, error) {
+	var similar []RecipeListItem
+	err := query.Preload("Tags", models.OrderByName).
+		Select("recipes.*, GREATEST(word_similarity(?, title), word_similarity(?, ingredients_used)) AS similarity", search, search).
+		Where("? <% title OR ? <% ingredients_used", search, search).
+		Order("similarity DESC").
+		Limit(limit).
+		Find(&similar).Error
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[uint]bool, len(matches))
+	results := append(make([]RecipeListItem, 0, limit), matches...)
+	for _, match := range matches {
+		seen[match.ID] = true
+	}
+	for _, recipe := range similar {
+		if len(results) >= limit {
+			break
+		}
+		if !seen[recipe.ID] {
+			results = append(results, recipe)
+		}
+	}
+
+	return results, nil
+}
+
+// searchSuggestion replaces each search term with the most similar word found
+// in the titles and ingredients of recipes, returning "" when nothing would
+// change.
+func (h *Handler) searchSuggestion(search string, recipes func() *gorm.DB) string {
+	terms := strings.Fields(strings.ToLower(searchTermPattern.ReplaceAllString(search, " ")))
+
+	changed := false
+	for i, term := range terms {
+		if len([]rune(term)) < 3 {
+			continue
+		}
+
+		var best struct {
+			Word  string
+			Score float64
+		}
+		words := recipes().Select(`DISTINCT unnest(regexp_split_to_array(lower(title || ' ' || ingredients_used), '[^[:alpha:]]+')) AS word`)
+		err := h.db.Table("(?) AS words", words).
+			Select("word, similarity(word, ?) AS score", term).
+			Where("length(word) > 2 AND word % ?", term).
+			Order("score DESC").
+			Limit(1).
+			Scan(&best).Error
+		if err != nil {
+			logrus.WithError(err).WithField("term", term).Warn("Failed to compute search suggestion")
+			return ""
+		}
+
+		if best.Word != "" && best.Word != term {
+			terms[i] = best.Word
+			changed = true
+		}
+	}
+
+	if !changed {
+		return ""
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"recipe-ai/internal/auth"
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxShareLifetime         = 365
+	maxActiveSharesPerRecipe = 20
+)
+
+type CreateShareRequest struct {
+	ExpiresInDays int `json:"expires_in_days"`
+}
+
+type RecipeShareResponse struct {
+	models.RecipeShare
+	URL string `json:"url"`
+}
+
+func (h *Handler) CreateShare(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var req CreateShareRequest
+	// The body is optional; an empty one creates a link that never expires.
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+			return
+		}
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxShareLifetime {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 0 (never) and 365"})
+		return
+	}
+
+	var recipe models.Recipe
+	if err := h.ownedRecipes(c).Select("id").First(&recipe, id.(uint)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch recipe for sharing")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share recipe"})
+		}
+		return
+	}
+
+	var active int64
+	err := h.db.Model(&models.RecipeShare{}).
+		Where("recipe_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", recipe.ID, time.Now()).
+		Count(&active).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count share links")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share recipe"})
+		return
+	}
+	if active >= maxActiveSharesPerRecipe {
+		c.JSON(http.StatusConflict, gin.H{"error": "Too many active share links for this recipe; revoke one first"})
+		return
+	}
+
+	token, err := auth.NewShareToken()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate share token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share recipe"})
+		return
+	}
+
+	share := models.RecipeShare{
+		RecipeID: recipe.ID,
+		UserID:   c.GetUint("user_id"),
+		Token:    token,
+	}
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		share.ExpiresAt = &expires
+	}
+
+	if err := h.db.Create(&share).Error; err != nil {
+		logrus.WithError(err).Error("Failed to save share link")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to share recipe"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"share_id":  share.ID,
+		"recipe_id": recipe.ID,
+		"user_id":   share.UserID,
+	}).Info("Share link created")
+
+	c.JSON(http.StatusCreated, gin.H{"share": newRecipeShareResponse(c, share)})
+}
+
+func (h *Handler) ListShares(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	var shares []models.RecipeShare
+	err := h.db.Where("recipe_id = ? AND user_id = ?", id.(uint), c.GetUint("user_id")).
+		Order("created_at DESC").
+		Find(&shares).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch share links")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch share links"})
+		return
+	}
+
+	response := make([]RecipeShareResponse, 0, len(shares))
+	for _, share := range shares {
+		response = append(response, newRecipeShareResponse(c, share))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shares": response})
+}
+
+func (h *Handler) RevokeShare(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid share ID"})
+		return
+	}
+
+	result := h.db.Model(&models.RecipeShare{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id.(uint), c.GetUint("user_id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to revoke share link")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke share link"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Share link not found"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"share_id": id.(uint),
+		"user_id":  c.GetUint("user_id"),
+	}).Info("Share link revoked")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Share link revoked successfully"})
+}
+
+// SharedRecipe renders the public page for a share link. Unknown, expired and
+// revoked links all get the same not-found page.
+func (h *Handler) SharedRecipe(c *gin.Context) {
+	// Keep the token out of the Referer header of any link on the page.
+	c.Header("Referrer-Policy", "no-referrer")
+
+	var share models.RecipeShare
+	err := h.db.Preload("Recipe").Where("token = ?", c.Param("token")).First(&share).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		logrus.WithError(err).Error("Failed to fetch share link")
+		c.HTML(http.StatusInternalServerError, "shared_recipe.html", gin.H{"Error": "Something went wrong, please try again later"})
+		return
+	}
+
+	now := time.Now()
+	if err != nil || share.Recipe == nil || !share.Active(now) {
+		c.HTML(http.StatusNotFound, "shared_recipe.html", gin.H{"Error": "This link has expired or does not exist"})
+		return
+	}
+
+	err = h.db.Model(&models.RecipeShare{}).Where("id = ?", share.ID).Updates(map[string]interface{}{
+		"view_count":     gorm.Expr("view_count + 1"),
+		"last_viewed_at": now,
+	}).Error
+	if err != nil {
+		logrus.WithError(err).WithField("share_id", share.ID).Warn("Failed to record share link view")
+	}
+
+	c.HTML(http.StatusOK, "shared_recipe.html", gin.H{"Recipe": share.Recipe})
+}
+
+func newRecipeShareResponse(c *gin.Context, share models.RecipeShare) RecipeShareResponse {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return RecipeShareResponse{
+		RecipeShare: share,
+		URL:         scheme + "://" + c.Request.Host + "/r/" + share.Token,
+	}
+}
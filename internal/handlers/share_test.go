@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewRecipeShareResponse(t *testing.T) {
+	share := models.RecipeShare{ID: 1, RecipeID: 2, Token: "abc-123_XYZ"}
+
+	tests := []struct {
+		name  string
+		setup func(c *gin.Context)
+		want  string
+	}{
+		{"plain", func(c *gin.Context) {}, "http://recipes.example.com/r/abc-123_XYZ"},
+		{"TLS", func(c *gin.Context) { c.Request.TLS = &tls.ConnectionState{} }, "https://recipes.example.com/r/abc-123_XYZ"},
+		{"behind a TLS proxy", func(c *gin.Context) { c.Request.Header.Set("X-Forwarded-Proto", "https") }, "https://recipes.example.com/r/abc-123_XYZ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "http://recipes.example.com/api/recipes/2/shares", nil)
+			tt.setup(c)
+			if got := newRecipeShareResponse(c, share).URL; got != tt.want {
+				t.Errorf("URL = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecipeShareActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	tests := []struct {
+		name  string
+		share models.RecipeShare
+		want  bool
+	}{
+		{"no expiry", models.RecipeShare{}, true},
+		{"expires later", models.RecipeShare{ExpiresAt: &future}, true},
+		{"expired", models.RecipeShare{ExpiresAt: &past}, false},
+		{"revoked", models.RecipeShare{RevokedAt: &past, ExpiresAt: &future}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.share.Active(now); got != tt.want {
+			t.Errorf("%s: Active = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
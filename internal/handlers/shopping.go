@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/models"
+	"recipe-ai/internal/recipeparse"
+	"recipe-ai/internal/shopping"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxShoppingListName    = 100
+	maxShoppingListRecipes = 50
+)
+
+// ShoppingListRequest builds a list from recipes, from the meal plan between
+// two dates, or both. A recipe's servings default to its own serving size and
+// planned meals use their servings override.
+type ShoppingListRequest struct {
+	Name     string               `json:"name"`
+	Recipes  []ShoppingListRecipe `json:"recipes"`
+	MealPlan *struct {
+		From models.Date `json:"from"`
+		To   models.Date `json:"to"`
+	} `json:"meal_plan"`
+}
+
+type ShoppingListRecipe struct {
+	RecipeID uint `json:"recipe_id"`
+	Servings int  `json:"servings"`
+}
+
+type ShoppingListSummary struct {
+	models.ShoppingList
+	ItemCount    int64 `json:"item_count" gorm:"->;column:item_count"`
+	CheckedCount int64 `json:"checked_count" gorm:"->;column:checked_count"`
+}
+
+type ShoppingAisle struct {
+	Aisle string                    `json:"aisle"`
+	Items []models.ShoppingListItem `json:"items"`
+}
+
+func (h *Handler) ListShoppingLists(c *gin.Context) {
+	lists := []ShoppingListSummary{}
+	err := h.db.Model(&models.ShoppingList{}).
+		Select(`shopping_lists.*,
+			(SELECT COUNT(*) FROM shopping_list_items WHERE shopping_list_id = shopping_lists.id) AS item_count,
+			(SELECT COUNT(*) FROM shopping_list_items WHERE shopping_list_id = shopping_lists.id AND checked) AS checked_count`).
+		Where("shopping_lists.user_id = ?", c.GetUint("user_id")).
+		Order("shopping_lists.created_at DESC").
+		Scan(&lists).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch shopping lists")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shopping lists"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"shopping_lists": lists})
+}
+
+// CreateShoppingList merges the ingredients of the requested recipes into a
+// saved list grouped by aisle. Pass units=metric or units=imperial to choose
+// how merged amounts are shown.
+func (h *Handler) CreateShoppingList(c *gin.Context) {
+	system, ok := unitSystemParam(c)
+	if !ok {
+		return
+	}
+
+	var req ShoppingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+	if len(req.Recipes) == 0 && req.MealPlan == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide recipes or a meal_plan range"})
+		return
+	}
+	if len(req.Recipes) > maxShoppingListRecipes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A shopping list can be made from at most %d recipes", maxShoppingListRecipes)})
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if len(name) > maxShoppingListName {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Name cannot be longer than 100 characters"})
+		return
+	}
+
+	wanted := make([]ShoppingListRecipe, 0, len(req.Recipes))
+	for _, recipe := range req.Recipes {
+		if recipe.Servings < 0 || recipe.Servings > maxScaledServings {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Servings must be a whole number between 1 and 100"})
+			return
+		}
+		wanted = append(wanted, recipe)
+	}
+
+	if plan := req.MealPlan; plan != nil {
+		if plan.From.IsZero() || plan.To.IsZero() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "meal_plan needs from and to dates"})
+			return
+		}
+		if plan.To.Before(plan.From.Time) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from cannot be after to"})
+			return
+		}
+		if plan.To.Sub(plan.From.Time) >= maxMealPlanDays*24*time.Hour {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("a meal plan range can cover at most %d days", maxMealPlanDays)})
+			return
+		}
+
+		entries, err := h.mealPlanEntries(c, plan.From, plan.To)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to fetch meal plan")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch meal plan"})
+			return
+		}
+		for _, entry := range entries {
+			wanted = append(wanted, ShoppingListRecipe{RecipeID: entry.RecipeID, Servings: entry.EffectiveServings()})
+		}
+		if name == "" {
+			name = fmt.Sprintf("Meal plan %s to %s", plan.From, plan.To)
+		}
+	}
+	if len(wanted) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No meals are planned in this range"})
+		return
+	}
+	if name == "" {
+		name = "Shopping list " + time.Now().Format(models.DateLayout)
+	}
+
+	ids := make([]uint, 0, len(wanted))
+	for _, recipe := range wanted {
+		ids = append(ids, recipe.RecipeID)
+	}
+	var recipes []models.Recipe
+	if err := h.withStructure(h.ownedRecipes(c)).Where("recipes.id IN ?", ids).Find(&recipes).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipes for shopping list")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+		return
+	}
+	byID := make(map[uint]models.Recipe, len(recipes))
+	for _, recipe := range recipes {
+		byID[recipe.ID] = recipe
+	}
+
+	var ingredients []shopping.Ingredient
+	for _, want := range wanted {
+		recipe, ok := byID[want.RecipeID]
+		if !ok {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found", "recipe_id": want.RecipeID})
+			return
+		}
+		ingredients = append(ingredients, shoppingIngredients(recipe, want.Servings)...)
+	}
+
+	list := models.ShoppingList{UserID: c.GetUint("user_id"), Name: name}
+	for i, item := range shopping.Merge(ingredients, system) {
+		list.Items = append(list.Items, models.ShoppingListItem{
+			Position: i,
+			Aisle:    item.Aisle,
+			Item:     item.Item,
+			Quantity: item.Quantity,
+			Unit:     item.Unit,
+		})
+	}
+
+	if err := h.db.Create(&list).Error; err != nil {
+		logrus.WithError(err).Error("Failed to create shopping list")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create shopping list"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"shopping_list_id": list.ID,
+		"recipes":          len(wanted),
+		"items":            len(list.Items),
+		"user_id":          list.UserID,
+	}).Info("Shopping list created")
+
+	respondShoppingList(c, http.StatusCreated, list)
+}
+
+// GetShoppingList returns a list with its items grouped by aisle.
+func (h *Handler) GetShoppingList(c *gin.Context) {
+	list, ok := h.ownedShoppingList(c)
+	if !ok {
+		return
+	}
+
+	respondShoppingList(c, http.StatusOK, *list)
+}
+
+// UpdateShoppingListItem checks an item off or back on.
+func (h *Handler) UpdateShoppingListItem(c *gin.Context) {
+	list, ok := h.ownedShoppingList(c)
+	if !ok {
+		return
+	}
+
+	itemID, err := strconv.ParseUint(c.Param("item_id"), 10, 32)
+	if err != nil || itemID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid item ID"})
+		return
+	}
+
+	var req struct {
+		Checked *bool `json:"checked"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Checked == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "checked is required"})
+		return
+	}
+
+	var item *models.ShoppingListItem
+	for i := range list.Items {
+		if list.Items[i].ID == uint(itemID) {
+			item = &list.Items[i]
+		}
+	}
+	if item == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shopping list item not found"})
+		return
+	}
+
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(item).Update("checked", *req.Checked).Error; err != nil {
+			return err
+		}
+		return tx.Model(list).Update("updated_at", time.Now()).Error
+	})
+	if err != nil {
+		logrus.WithError(err).Error("Failed to update shopping list item")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shopping list item"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"item": item})
+}
+
+func (h *Handler) DeleteShoppingList(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shopping list ID"})
+		return
+	}
+
+	result := h.db.Where("user_id = ?", c.GetUint("user_id")).Delete(&models.ShoppingList{}, id.(uint))
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to delete shopping list")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete shopping list"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Shopping list not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Shopping list deleted successfully"})
+}
+
+// ExportShoppingList writes a list as plain text (txt) or as a Markdown
+// checklist (md), grouped by aisle with checked items marked.
+func (h *Handler) ExportShoppingList(c *gin.Context) {
+	format := c.Param("format")
+	if format != "txt" && format != "md" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid export format"})
+		return
+	}
+
+	list, ok := h.ownedShoppingList(c)
+	if !ok {
+		return
+	}
+
+	var text strings.Builder
+	if format == "md" {
+		text.WriteString("# " + list.Name + "\n")
+	} else {
+		text.WriteString(list.Name + "\n")
+	}
+	for _, aisle := range groupShoppingItems(list.Items) {
+		if format == "md" {
+			text.WriteString("\n## " + aisle.Aisle + "\n\n")
+		} else {
+			text.WriteString("\n" + aisle.Aisle + "\n")
+		}
+		for _, item := range aisle.Items {
+			mark := "[ ]"
+			if item.Checked {
+				mark = "[x]"
+			}
+			line := shopping.Line(item.Quantity, item.Unit, item.Item)
+			if format == "md" {
+				fmt.Fprintf(&text, "- %s %s\n", mark, line)
+			} else {
+				fmt.Fprintf(&text, "  %s %s\n", mark, line)
+			}
+		}
+	}
+
+	filename := "shopping_list_" + time.Now().Format("20060102_150405")
+	contentType := "text/plain"
+	if format == "md" {
+		contentType = "text/markdown"
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, filename, format))
+	c.Data(http.StatusOK, contentType, []byte(text.String()))
+}
+
+// ownedShoppingList loads the :id list with its items if it belongs to the
+// caller, writing a 404 response and returning false when it does not.
+func (h *Handler) ownedShoppingList(c *gin.Context) (*models.ShoppingList, bool) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid shopping list ID"})
+		return nil, false
+	}
+
+	var list models.ShoppingList
+	err := h.db.Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where("user_id = ?", c.GetUint("user_id")).
+		First(&list, id.(uint)).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Shopping list not found"})
+		} else {
+			logrus.WithError(err).Error("Failed to fetch shopping list")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch shopping list"})
+		}
+		return nil, false
+	}
+	return &list, true
+}
+
+func respondShoppingList(c *gin.Context, status int, list models.ShoppingList) {
+	checked := 0
+	for _, item := range list.Items {
+		if item.Checked {
+			checked++
+		}
+	}
+	aisles := groupShoppingItems(list.Items)
+	itemCount := len(list.Items)
+	list.Items = nil
+
+	c.JSON(status, gin.H{
+		"shopping_list": list,
+		"aisles":        aisles,
+		"item_count":    itemCount,
+		"checked_count": checked,
+	})
+}
+
+// groupShoppingItems splits items, which are stored in aisle order, into
+// their aisles.
+func groupShoppingItems(items []models.ShoppingListItem) []ShoppingAisle {
+	aisles := []ShoppingAisle{}
+	for _, item := range items {
+		if n := len(aisles); n == 0 || aisles[n-1].Aisle != item.Aisle {
+			aisles = append(aisles, ShoppingAisle{Aisle: item.Aisle})
+		}
+		last := &aisles[len(aisles)-1]
+		last.Items = append(last.Items, item)
+	}
+	return aisles
+}
+
+// shoppingIngredients returns a recipe's ingredients scaled from its serving
+// size to servings, or unscaled when servings is 0.
+func shoppingIngredients(recipe models.Recipe, servings int) []shopping.Ingredient {
+	// Recipes saved before ingredients were normalized are parsed on the fly.
+	rows := recipe.Ingredients
+	if len(rows) == 0 {
+		parsed, _ := recipeparse.ParseContent(recipe.RecipeContent)
+		rows = models.NewRecipeIngredients(parsed)
+	}
+
+	factor := 1.0
+	if servings > 0 {
+		original := recipe.ServingSize
+		if original <= 0 {
+			original = 4
+		}
+		factor = float64(servings) / float64(original)
+	}
+
+	ingredients := make([]shopping.Ingredient, 0, len(rows))
+	for _, row := range rows {
+		ingredients = append(ingredients, shopping.Ingredient{
+			Quantity: row.Quantity,
+			Unit:     row.Unit,
+			Item:     row.Item,
+			Factor:   factor,
+		})
+	}
+	return ingredients
+}
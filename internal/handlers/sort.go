@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type sortOption struct {
+	expression   string
+	defaultOrder string
+	needsSearch  bool
+}
+
+// sortOptions maps the public sort= names to SQL expressions. The relevance
+// expression takes the search text as its only argument.
+var sortOptions = map[string]sortOption{
+	"created":   {expression: "recipes.created_at", defaultOrder: "desc"},
+	"updated":   {expression: "recipes.updated_at", defaultOrder: "desc"},
+	"rating":    {expression: "COALESCE(recipes.rating, 0)", defaultOrder: "desc"},
+	"title":     {expression: "recipes.title", defaultOrder: "asc"},
+	"relevance": {expression: "ts_rank(search_vector, websearch_to_tsquery('english', ?))", defaultOrder: "desc", needsSearch: true},
+}
+
+type recipeSort struct {
+	Name   string
+	Order  string
+	search string
+	option sortOption
+}
+
+// listCursor is the opaque keyset position handed to clients. It records
+// the sort it was issued for so it cannot be replayed against another one.
+type listCursor struct {
+	Sort     string          `json:"s"`
+	Order    string          `json:"o"`
+	Value    json.RawMessage `json:"v"`
+	ID       uint            `json:"id"`
+	Backward bool            `json:"b,omitempty"`
+}
+
+func parseRecipeSort(c *gin.Context, search string) (recipeSort, error) {
+	name := strings.ToLower(c.Query("sort"))
+	if name == "" {
+		name = "created"
+		if search != "" {
+			name = "relevance"
+		}
+	}
+
+	option, ok := sortOptions[name]
+	if !ok {
+		return recipeSort{}, fmt.Errorf("sort must be one of created, updated, rating, title or relevance")
+	}
+	if option.needsSearch && search == "" {
+		return recipeSort{}, fmt.Errorf("sort=relevance requires a search term")
+	}
+
+	order := strings.ToLower(c.DefaultQuery("order", option.defaultOrder))
+	if order != "asc" && order != "desc" {
+		return recipeSort{}, fmt.Errorf("order must be asc or desc")
+	}
+
+	return recipeSort{Name: name, Order: order, search: search, option: option}, nil
+}
+
+func (s recipeSort) vars() []interface{} {
+	if s.option.needsSearch {
+		return []interface{}{s.search}
+	}
+	return nil
+}
+
+// orderBy sorts by the chosen key with the recipe ID as a tie-breaker, which
+// keeps keyset pagination stable. reverse flips both for backward pages.
+func (s recipeSort) orderBy(reverse bool) clause.OrderBy {
+	direction := strings.ToUpper(s.Order)
+	if reverse {
+		direction = flipOrder(direction)
+	}
+
+	return clause.OrderBy{Expression: clause.Expr{
+		SQL:                fmt.Sprintf("%s %s, recipes.id %s", s.option.expression, direction, direction),
+		Vars:               s.vars(),
+		WithoutParentheses: true,
+	}}
+}
+
+// after restricts query to rows past the cursor position in the direction
+// the cursor was issued for.
+func (s recipeSort) after(query *gorm.DB, cursor *listCursor) (*gorm.DB, error) {
+	value, err := s.decodeValue(cursor.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	descending := s.Order == "desc"
+	if cursor.Backward {
+		descending = !descending
+	}
+	operator := ">"
+	if descending {
+		operator = "<"
+	}
+
+	args := append(s.vars(), value, cursor.ID)
+	return query.Where(fmt.Sprintf("(%s, recipes.id) %s (?, ?)", s.option.expression, operator), args...), nil
+}
+
+func (s recipeSort) cursorFor(recipe RecipeListItem, backward bool) (string, error) {
+	var value interface{}
+	switch s.Name {
+	case "created":
+		value = recipe.CreatedAt
+	case "updated":
+		value = recipe.UpdatedAt
+	case "rating":
+		value = 0
+		if recipe.Rating != nil {
+			value = *recipe.Rating
+		}
+	case "title":
+		value = recipe.Title
+	case "relevance":
+		value = 0.0
+		if recipe.Rank != nil {
+			value = *recipe.Rank
+		}
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := json.Marshal(listCursor{Sort: s.Name, Order: s.Order, Value: raw, ID: recipe.ID, Backward: backward})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func (s recipeSort) decodeValue(raw json.RawMessage) (interface{}, error) {
+	var err error
+	switch s.Name {
+	case "created", "updated":
+		var t time.Time
+		err = json.Unmarshal(raw, &t)
+		if err == nil {
+			return t, nil
+		}
+	case "rating":
+		var n int
+		err = json.Unmarshal(raw, &n)
+		if err == nil {
+			return n, nil
+		}
+	case "title":
+		var text string
+		err = json.Unmarshal(raw, &text)
+		if err == nil {
+			return text, nil
+		}
+	case "relevance":
+		var f float64
+		err = json.Unmarshal(raw, &f)
+		if err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("invalid cursor")
+}
+
+func decodeCursor(raw string, s recipeSort) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	var cursor listCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	if cursor.Sort != s.Name || cursor.Order != s.Order {
+		return nil, fmt.Errorf("cursor was issued for sort=%s&order=%s", cursor.Sort, cursor.Order)
+	}
+	return &cursor, nil
+}
+
+func flipOrder(direction string) string {
+	if direction == "DESC" {
+		return "ASC"
+	}
+	return "DESC"
+}
+
+// listRecipesByCursor serves GetRecipes in keyset mode: no COUNT query and no
+// OFFSET scan, just the rows either side of the cursor.
+func (h *Handler) listRecipesByCursor(c *gin.Context, matching func() *gorm.DB, filters recipeFilters, sort recipeSort, rawCursor string, perPage int) {
+	var cursor *listCursor
+	if rawCursor != "" {
+		var err error
+		if cursor, err = decodeCursor(rawCursor, sort); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	backward := cursor != nil && cursor.Backward
+
+	query := selectListColumns(matching(), filters.Search)
+	if cursor != nil {
+		var err error
+		if query, err = sort.after(query, cursor); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	var recipes []RecipeListItem
+	if err := query.Clauses(sort.orderBy(backward)).Limit(perPage + 1).Find(&recipes).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+		return
+	}
+
+	hasMore := len(recipes) > perPage
+	if hasMore {
+		recipes = recipes[:perPage]
+	}
+	if backward {
+		for i, j := 0, len(recipes)-1; i < j; i, j = i+1, j-1 {
+			recipes[i], recipes[j] = recipes[j], recipes[i]
+		}
+	}
+
+	response := gin.H{
+		"recipes":     recipes,
+		"per_page":    perPage,
+		"sort":        sort.Name,
+		"order":       sort.Order,
+		"next_cursor": nil,
+		"prev_cursor": nil,
+	}
+
+	if len(recipes) > 0 {
+		if hasMore || backward {
+			next, err := sort.cursorFor(recipes[len(recipes)-1], false)
+			if err != nil {
+				logrus.WithError(err).Error("Failed to encode cursor")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+				return
+			}
+			response["next_cursor"] = next
+		}
+		if (cursor != nil && !backward) || (backward && hasMore) {
+			prev, err := sort.cursorFor(recipes[0], true)
+			if err != nil {
+				logrus.WithError(err).Error("Failed to encode cursor")
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+				return
+			}
+			response["prev_cursor"] = prev
+		}
+	}
+
+	if filters.Facets {
+		facets, err := h.recipeFacets(matching)
+		if err != nil {
+			logrus.WithError(err).Error("Failed to compute recipe facets")
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch recipes"})
+			return
+		}
+		response["facets"] = facets
+	}
+
+	c.JSON(http.StatusOK, response)
+}
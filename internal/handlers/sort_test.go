@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func testRecipeSort(t *testing.T, query, search string) recipeSort {
+	t.Helper()
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/recipes?"+query, nil)
+	sort, err := parseRecipeSort(c, search)
+	if err != nil {
+		t.Fatalf("parseRecipeSort(%q): %v", query, err)
+	}
+	return sort
+}
+
+func TestParseRecipeSort(t *testing.T) {
+	tests := []struct {
+		query, search string
+		wantName      string
+		wantOrder     string
+		wantErr       string
+	}{
+		{"", "", "created", "desc", ""},
+		{"", "pasta", "relevance", "desc", ""},
+		{"sort=Title", "", "title", "asc", ""},
+		{"sort=rating&order=ASC", "", "rating", "asc", ""},
+		{"sort=popular", "", "", "", "sort must be one of"},
+		{"sort=relevance", "", "", "", "requires a search term"},
+		{"sort=title&order=up", "", "", "", "order must be asc or desc"},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/api/recipes?"+tt.query, nil)
+		sort, err := parseRecipeSort(c, tt.search)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseRecipeSort(%q, %q) error = %v, want it to contain %q", tt.query, tt.search, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || sort.Name != tt.wantName || sort.Order != tt.wantOrder {
+			t.Errorf("parseRecipeSort(%q, %q) = %s %s, %v, want %s %s", tt.query, tt.search, sort.Name, sort.Order, err, tt.wantName, tt.wantOrder)
+		}
+	}
+}
+
+func TestListCursorRoundTrip(t *testing.T) {
+	created := time.Date(2024, 3, 1, 12, 30, 0, 123456000, time.UTC)
+	rating := 4
+	rank := 0.0759
+	recipe := RecipeListItem{
+		Recipe: models.Recipe{ID: 42, Title: "Garlic Rice", Rating: &rating, CreatedAt: created, UpdatedAt: created.Add(time.Hour)},
+		Rank:   &rank,
+	}
+
+	tests := []struct {
+		query, search string
+		recipe        RecipeListItem
+		want          interface{}
+	}{
+		{"sort=created", "", recipe, created},
+		{"sort=updated&order=asc", "", recipe, created.Add(time.Hour)},
+		{"sort=rating", "", recipe, 4},
+		{"sort=rating", "", RecipeListItem{Recipe: models.Recipe{ID: 42}}, 0},
+		{"sort=title", "", recipe, "Garlic Rice"},
+		{"sort=relevance", "rice", recipe, 0.0759},
+		{"sort=relevance", "rice", RecipeListItem{Recipe: models.Recipe{ID: 42}}, 0.0},
+	}
+
+	for _, tt := range tests {
+		sort := testRecipeSort(t, tt.query, tt.search)
+		for _, backward := range []bool{false, true} {
+			raw, err := sort.cursorFor(tt.recipe, backward)
+			if err != nil {
+				t.Fatalf("%s: cursorFor: %v", tt.query, err)
+			}
+			cursor, err := decodeCursor(raw, sort)
+			if err != nil {
+				t.Fatalf("%s: decodeCursor: %v", tt.query, err)
+			}
+			if cursor.ID != 42 || cursor.Backward != backward {
+				t.Errorf("%s: cursor = id %d backward %v, want id 42 backward %v", tt.query, cursor.ID, cursor.Backward, backward)
+			}
+			value, err := sort.decodeValue(cursor.Value)
+			if err != nil {
+				t.Fatalf("%s: decodeValue: %v", tt.query, err)
+			}
+			if when, ok := tt.want.(time.Time); ok {
+				if got, ok := value.(time.Time); !ok || !got.Equal(when) {
+					t.Errorf("%s: value = %v, want %v", tt.query, value, when)
+				}
+			} else if value != tt.want {
+				t.Errorf("%s: value = %#v, want %#v", tt.query, value, tt.want)
+			}
+		}
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	created := testRecipeSort(t, "sort=created", "")
+	raw, err := created.cursorFor(RecipeListItem{Recipe: models.Recipe{ID: 1, Title: "Soup"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+
+	tests := []struct {
+		name    string
+		raw     string
+		sort    recipeSort
+		wantErr string
+	}{
+		{"not base64", "%%%", created, "invalid cursor"},
+		{"not JSON", encode("cursor"), created, "invalid cursor"},
+		{"another sort", raw, testRecipeSort(t, "sort=title", ""), "cursor was issued for sort=created&order=desc"},
+		{"another order", raw, testRecipeSort(t, "sort=created&order=asc", ""), "cursor was issued for sort=created&order=desc"},
+	}
+
+	for _, tt := range tests {
+		if _, err := decodeCursor(tt.raw, tt.sort); err == nil || err.Error() != tt.wantErr {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// A cursor whose value does not match its sort is forged.
+	forged, err := decodeCursor(encode(`{"s":"created","o":"desc","v":"yesterday","id":1}`), created)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := created.decodeValue(forged.Value); err == nil {
+		t.Error("decodeValue accepted a value that is not a time")
+	}
+}
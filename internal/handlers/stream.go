@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/llm"
+	"recipe-ai/internal/recipeparse"
+	"recipe-ai/internal/structured"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const streamTimeout = 5 * time.Minute
+
+// streamedTagsPattern matches the line of suggested tags the model is asked
+// to finish a streamed recipe with.
+var streamedTagsPattern = regexp.MustCompile(`(?im)^[ \t*#]*tags:\**\s*(.*)$`)
+
+func (h *Handler) GenerateRecipeStream(c *gin.Context) {
+	logrus.WithFields(logrus.Fields{
+		"endpoint": "generate_recipe_stream",
+		"ip":       c.ClientIP(),
+	}).Info("Streaming recipe generation started")
+
+	var req RecipeRequest
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		logrus.WithError(err).Warn("Invalid request format for streaming recipe generation")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	if req.Ingredients == "" && !req.UsePantry {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide at least one ingredient"})
+		return
+	}
+
+	if req.Variations > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Variations cannot be streamed; use /generate_recipe"})
+		return
+	}
+
+	// Cooking from the pantry offers the model the caller's pantry on top of
+	// any ingredients they listed.
+	pantry, ok := h.recipePantry(c, &req)
+	if !ok {
+		return
+	}
+
+	if req.ServingSize == 0 {
+		req.ServingSize = 4
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), streamTimeout)
+	defer cancel()
+
+	prompt := buildRecipePrompt(req)
+	if req.UsePantry {
+		prompt = buildPantryPrompt(prompt, pantry)
+	}
+	if req.SuggestTags {
+		prompt += fmt.Sprintf("\n\nFinish with a line starting \"Tags:\" followed by up to %d short, comma-separated tags for the recipe, such as weeknight or freezer-friendly.", structured.MaxTags)
+	}
+	recipeText, err := h.llm.Stream(ctx, llm.UserPrompt(prompt), func(text string) {
+		c.SSEvent("delta", gin.H{"text": text})
+		c.Writer.Flush()
+	})
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"provider": h.llm.Name(),
+			"model":    h.llm.Model(),
+			"ip":       c.ClientIP(),
+		}).Error("Failed to stream recipe")
+		c.SSEvent("error", gin.H{"error": fmt.Sprintf("Failed to generate recipe: %v", err)})
+		c.Writer.Flush()
+		return
+	}
+
+	// done carries the text as streamed rather than paying for a second
+	// generation to record it through the recipe schema, so saving it parses
+	// the ingredients and steps from the text.
+	var tags []string
+	if req.SuggestTags {
+		recipeText, tags = splitStreamedTags(recipeText)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"response_length": len(recipeText),
+		"ip":              c.ClientIP(),
+	}).Info("Recipe streamed successfully")
+
+	recipeData := newRecipeData(req, recipeText)
+	recipeData.Tags = tags
+	if req.UsePantry {
+		ingredients, _ := recipeparse.ParseContent(recipeText)
+		recipeData.Pantry = pantryUsage(&structured.Recipe{Ingredients: ingredients}, pantry, req.Ingredients)
+	}
+	c.SSEvent("done", recipeData)
+	c.Writer.Flush()
+}
+
+// splitStreamedTags removes the last line of suggested tags from a streamed
+// recipe and returns the text without it along with the normalized tags.
+func splitStreamedTags(text string) (string, []string) {
+	matches := streamedTagsPattern.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return text, []string{}
+	}
+	last := matches[len(matches)-1]
+	suggestions := strings.Split(strings.Trim(text[last[2]:last[3]], " *"), ",")
+	return strings.TrimRight(text[:last[0]], "\n ") + text[last[1]:], suggestedTags(suggestions)
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStreamedTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		wantText string
+		wantTags []string
+	}{
+		{
+			name:     "plain line",
+			text:     "Recipe Name: Soup\n\nSteps:\n1. Boil.\n\nTags: Weeknight, freezer friendly, soup\n",
+			wantText: "Recipe Name: Soup\n\nSteps:\n1. Boil.\n",
+			wantTags: []string{"weeknight", "freezer-friendly", "soup"},
+		},
+		{
+			name:     "markdown",
+			text:     "Soup\n\n**Tags:** quick, quick,  vegan ",
+			wantText: "Soup",
+			wantTags: []string{"quick", "vegan"},
+		},
+		{
+			name:     "no tags",
+			text:     "Soup\nServe hot.",
+			wantText: "Soup\nServe hot.",
+			wantTags: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, tags := splitStreamedTags(tt.text)
+			if text != tt.wantText {
+				t.Errorf("text = %q, want %q", text, tt.wantText)
+			}
+			if !reflect.DeepEqual(tags, tt.wantTags) {
+				t.Errorf("tags = %q, want %q", tags, tt.wantTags)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	maxTagsPerRecipe      = 20
+	maxTagSuggestions     = 20
+	defaultTagSuggestions = 10
+)
+
+type TagCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+// GetTags lists the caller's tags with the number of recipes using each, most
+// used first.
+func (h *Handler) GetTags(c *gin.Context) {
+	tags, err := h.tagCounts(c, "", 0)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch tags")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// AutocompleteTags suggests the caller's tags starting with q, most used
+// first.
+func (h *Handler) AutocompleteTags(c *gin.Context) {
+	limit, err := intQuery(c, "limit", 1, maxTagSuggestions)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if limit == 0 {
+		limit = defaultTagSuggestions
+	}
+
+	// Normalize what has been typed so far the same way tags are stored, but
+	// keep a trailing separator so "freezer " still matches "freezer-friendly".
+	prefix := strings.ToLower(strings.TrimLeft(c.Query("q"), " "))
+	if prefix != "" {
+		trailing := strings.HasSuffix(prefix, " ") || strings.HasSuffix(prefix, "-")
+		if prefix, err = models.NormalizeTag(prefix); err != nil {
+			c.JSON(http.StatusOK, gin.H{"tags": []TagCount{}})
+			return
+		}
+		if trailing {
+			prefix += "-"
+		}
+	}
+
+	tags, err := h.tagCounts(c, prefix, limit)
+	if err != nil {
+		logrus.WithError(err).Error("Failed to autocomplete tags")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
+
+// AddRecipeTags attaches tags to a recipe, creating new ones as needed.
+// Tags the recipe already has are ignored.
+func (h *Handler) AddRecipeTags(c *gin.Context) {
+	recipeID, ok := h.ownedRecipeID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	names, err := normalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(names) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please provide at least one tag"})
+		return
+	}
+
+	var tooMany bool
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		var current []string
+		err := tx.Model(&models.Tag{}).
+			Joins("JOIN recipe_tags ON recipe_tags.tag_id = tags.id").
+			Where("recipe_tags.recipe_id = ?", recipeID).
+			Pluck("tags.name", &current).Error
+		if err != nil {
+			return err
+		}
+
+		total := len(current)
+		for _, name := range names {
+			if !slices.Contains(current, name) {
+				total++
+			}
+		}
+		if total > maxTagsPerRecipe {
+			tooMany = true
+			return nil
+		}
+
+		return models.AddRecipeTags(tx, c.GetUint("user_id"), recipeID, names)
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("recipe_id", recipeID).Error("Failed to tag recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to tag recipe"})
+		return
+	}
+	if tooMany {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A recipe can have at most %d tags", maxTagsPerRecipe)})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"recipe_id": recipeID,
+		"tags":      names,
+		"user_id":   c.GetUint("user_id"),
+	}).Info("Recipe tagged")
+
+	h.respondRecipeTags(c, recipeID)
+}
+
+func (h *Handler) RemoveRecipeTag(c *gin.Context) {
+	recipeID, ok := h.ownedRecipeID(c)
+	if !ok {
+		return
+	}
+
+	name, err := models.NormalizeTag(c.Param("tag"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var removed int64
+	err = h.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`DELETE FROM recipe_tags WHERE recipe_id = ?
+			AND tag_id IN (SELECT id FROM tags WHERE user_id = ? AND name = ?)`, recipeID, c.GetUint("user_id"), name)
+		if result.Error != nil {
+			return result.Error
+		}
+		removed = result.RowsAffected
+		return models.PruneTags(tx, c.GetUint("user_id"))
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("recipe_id", recipeID).Error("Failed to remove recipe tag")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove tag"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe does not have this tag"})
+		return
+	}
+
+	h.respondRecipeTags(c, recipeID)
+}
+
+func (h *Handler) respondRecipeTags(c *gin.Context, recipeID uint) {
+	tags := []models.Tag{}
+	err := h.db.Joins("JOIN recipe_tags ON recipe_tags.tag_id = tags.id").
+		Where("recipe_tags.recipe_id = ?", recipeID).
+		Order("tags.name").
+		Find(&tags).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch recipe tags")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"recipe_id": recipeID, "tags": tags})
+}
+
+// tagCounts counts the caller's recipes per tag, leaving out trashed recipes
+// and tags used by none. A limit of 0 returns every tag.
+func (h *Handler) tagCounts(c *gin.Context, prefix string, limit int) ([]TagCount, error) {
+	query := h.db.Model(&models.Tag{}).
+		Select("tags.name, COUNT(recipes.id) AS count").
+		Joins("JOIN recipe_tags ON recipe_tags.tag_id = tags.id").
+		Joins("JOIN recipes ON recipes.id = recipe_tags.recipe_id AND recipes.deleted_at IS NULL").
+		Where("tags.user_id = ?", c.GetUint("user_id")).
+		Group("tags.name").
+		Order("count DESC, tags.name")
+	if prefix != "" {
+		// Normalized names cannot contain LIKE wildcards.
+		query = query.Where("tags.name LIKE ?", prefix+"%")
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	tags := []TagCount{}
+	err := query.Scan(&tags).Error
+	return tags, err
+}
+
+// normalizeTags normalizes tag names and drops duplicates, keeping the first
+// occurrence's position.
+func normalizeTags(raw []string) ([]string, error) {
+	names := make([]string, 0, len(raw))
+	seen := make(map[string]bool, len(raw))
+	for _, value := range raw {
+		name, err := models.NormalizeTag(value)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(names) > maxTagsPerRecipe {
+		return nil, fmt.Errorf("a recipe can have at most %d tags", maxTagsPerRecipe)
+	}
+	return names, nil
+}
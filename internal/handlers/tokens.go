@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"recipe-ai/internal/auth"
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxActiveTokens  = 25
+	maxTokenLifetime = 365
+	// maxTokenRateLimit is the API endpoints' own limit per minute. A token's
+	// rate limit can only make an endpoint stricter, so anything higher would
+	// never apply.
+	maxTokenRateLimit = 100
+)
+
+type CreateTokenRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+	RateLimit     int      `json:"rate_limit"`
+}
+
+type APITokenResponse struct {
+	models.APIToken
+	Scopes []string `json:"scopes"`
+}
+
+func newAPITokenResponse(token models.APIToken) APITokenResponse {
+	return APITokenResponse{APIToken: token, Scopes: token.ScopeList()}
+}
+
+func (h *Handler) ListTokens(c *gin.Context) {
+	var tokens []models.APIToken
+	if err := h.db.Where("user_id = ?", c.GetUint("user_id")).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch API tokens")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch API tokens"})
+		return
+	}
+
+	response := make([]APITokenResponse, 0, len(tokens))
+	for _, token := range tokens {
+		response = append(response, newAPITokenResponse(token))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tokens": response})
+}
+
+func (h *Handler) CreateToken(c *gin.Context) {
+	var req CreateTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Token name must be between 1 and 100 characters"})
+		return
+	}
+
+	scopes, err := auth.ValidateScopes(req.Scopes)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > maxTokenLifetime {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expires_in_days must be between 0 (never) and 365"})
+		return
+	}
+
+	if req.RateLimit < 0 || req.RateLimit > maxTokenRateLimit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rate_limit must be between 0 (endpoint default) and %d requests per minute; it can only lower the endpoint limits", maxTokenRateLimit)})
+		return
+	}
+
+	userID := c.GetUint("user_id")
+
+	var active int64
+	err = h.db.Model(&models.APIToken{}).
+		Where("user_id = ? AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > ?)", userID, time.Now()).
+		Count(&active).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to count API tokens")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API token"})
+		return
+	}
+	if active >= maxActiveTokens {
+		c.JSON(http.StatusConflict, gin.H{"error": "Too many active API tokens; revoke one first"})
+		return
+	}
+
+	raw, prefix, hash, err := auth.NewToken()
+	if err != nil {
+		logrus.WithError(err).Error("Failed to generate API token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API token"})
+		return
+	}
+
+	token := models.APIToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		TokenHash: hash,
+		Scopes:    strings.Join(scopes, ","),
+		RateLimit: req.RateLimit,
+	}
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		token.ExpiresAt = &expires
+	}
+
+	if err := h.db.Create(&token).Error; err != nil {
+		logrus.WithError(err).Error("Failed to save API token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API token"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"token_id": token.ID,
+		"user_id":  userID,
+		"scopes":   token.Scopes,
+	}).Info("API token created")
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":   "Store this token now, it will not be shown again",
+		"token":     raw,
+		"api_token": newAPITokenResponse(token),
+	})
+}
+
+func (h *Handler) RevokeToken(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	result := h.db.Model(&models.APIToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", id.(uint), c.GetUint("user_id")).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to revoke API token")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API token"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "API token not found"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"token_id": id.(uint),
+		"user_id":  c.GetUint("user_id"),
+	}).Info("API token revoked")
+
+	c.JSON(http.StatusOK, gin.H{"message": "API token revoked successfully"})
+}
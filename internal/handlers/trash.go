@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// TrashedRecipe is a recipe as listed by GetTrash. PurgeAt is when it will be
+// deleted for good, and nil when automatic purging is disabled.
+type TrashedRecipe struct {
+	models.Recipe
+	DeletedAt time.Time  `json:"deleted_at"`
+	PurgeAt   *time.Time `json:"purge_at"`
+}
+
+func (h *Handler) GetTrash(c *gin.Context) {
+	page, exists := c.Get("page")
+	if !exists {
+		page = 1
+	}
+	perPage, exists := c.Get("per_page")
+	if !exists {
+		perPage = 10
+	}
+
+	var total int64
+	if err := h.trashedRecipes(c).Count(&total).Error; err != nil {
+		logrus.WithError(err).Error("Failed to count trashed recipes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	var recipes []models.Recipe
+	err := h.trashedRecipes(c).
+		Order("deleted_at DESC, id DESC").
+		Offset((page.(int) - 1) * perPage.(int)).
+		Limit(perPage.(int)).
+		Find(&recipes).Error
+	if err != nil {
+		logrus.WithError(err).Error("Failed to fetch trashed recipes")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trash"})
+		return
+	}
+
+	items := make([]TrashedRecipe, 0, len(recipes))
+	for _, recipe := range recipes {
+		item := TrashedRecipe{Recipe: recipe, DeletedAt: recipe.DeletedAt.Time}
+		if h.cfg.TrashRetention > 0 {
+			purgeAt := recipe.DeletedAt.Time.Add(h.cfg.TrashRetention)
+			item.PurgeAt = &purgeAt
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recipes":      items,
+		"total":        total,
+		"pages":        (int(total) + perPage.(int) - 1) / perPage.(int),
+		"current_page": page,
+		"per_page":     perPage,
+	})
+}
+
+func (h *Handler) RestoreRecipe(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	result := h.trashedRecipes(c).Where("id = ?", id.(uint)).Update("deleted_at", nil)
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to restore recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore recipe"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found in trash"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"recipe_id": id.(uint),
+		"user_id":   c.GetUint("user_id"),
+	}).Info("Recipe restored from trash")
+
+	var recipe models.Recipe
+	if err := h.withStructure(h.ownedRecipes(c)).First(&recipe, id.(uint)).Error; err != nil {
+		logrus.WithError(err).Error("Failed to fetch restored recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch restored recipe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Recipe restored successfully",
+		"recipe":  recipe,
+	})
+}
+
+// PurgeRecipe permanently deletes a recipe that is in the trash.
+func (h *Handler) PurgeRecipe(c *gin.Context) {
+	id, exists := c.Get("id")
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipe ID"})
+		return
+	}
+
+	result := h.trashedRecipes(c).Where("id = ?", id.(uint)).Delete(&models.Recipe{})
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to purge recipe")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete recipe"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found in trash"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"recipe_id": id.(uint),
+		"user_id":   c.GetUint("user_id"),
+	}).Info("Recipe purged from trash")
+
+	c.JSON(http.StatusOK, gin.H{"message": "Recipe permanently deleted"})
+}
+
+// EmptyTrash permanently deletes every recipe in the caller's trash.
+func (h *Handler) EmptyTrash(c *gin.Context) {
+	result := h.trashedRecipes(c).Delete(&models.Recipe{})
+	if result.Error != nil {
+		logrus.WithError(result.Error).Error("Failed to empty trash")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to empty trash"})
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"purged":  result.RowsAffected,
+		"user_id": c.GetUint("user_id"),
+	}).Info("Trash emptied")
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Trash emptied",
+		"purged":  result.RowsAffected,
+	})
+}
+
+// trashedRecipes scopes a query to the caller's recipes that are in the trash.
+func (h *Handler) trashedRecipes(c *gin.Context) *gorm.DB {
+	return h.db.Unscoped().Model(&models.Recipe{}).
+		Where("recipes.user_id = ? AND recipes.deleted_at IS NOT NULL", c.GetUint("user_id"))
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"recipe-ai/internal/llm"
+	"recipe-ai/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	maxVariations = 5
+	// variationWorkers bounds how many variations are generated at once, so
+	// a batch does not burst past the provider's rate limits.
+	variationWorkers = 3
+)
+
+// cuisineNudges steer variations towards different cuisines when the request
+// does not name one; techniqueNudges are used when it does.
+var (
+	cuisineNudges = []string{
+		"Give it an Italian twist.",
+		"Give it a Mexican twist.",
+		"Give it a Japanese twist.",
+		"Give it an Indian twist.",
+		"Give it a Mediterranean twist.",
+	}
+	techniqueNudges = []string{
+		"Roast or bake it in the oven.",
+		"Make it a one-pot stew or braise.",
+		"Cook it quickly as a stir-fry or sauté.",
+		"Grill or sear it for a smoky char.",
+		"Serve it as a fresh salad or bowl with little cooking.",
+	}
+)
+
+// RecipeVariation is one of several recipes generated from the same request.
+// Exactly one of RecipeData and Error is set.
+type RecipeVariation struct {
+	Variation  int         `json:"variation"`
+	Nudge      string      `json:"nudge"`
+	RecipeData *RecipeData `json:"recipe_data,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+// generateVariations generates req.Variations recipes from the same prompt,
+// each nudged in a different direction, and responds with all of them. A
+// variation that fails is reported with its error rather than failing the
+// rest; the request only fails when every variation does.
+func (h *Handler) generateVariations(c *gin.Context, req RecipeRequest, prompt string, pantry []models.PantryItem) {
+	// The rate limiter admitted the request for one generation; each further
+	// variation needs its own token, and none are taken unless all are there.
+	// More variations than the limiter's burst could never be admitted, so
+	// waiting would not help.
+	if value, ok := c.Get("rate_limiter"); ok {
+		if limiter, ok := value.(*rate.Limiter); ok {
+			if req.Variations > limiter.Burst() {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Your rate limit allows at most %d variations per request", limiter.Burst())})
+				return
+			}
+			if !limiter.AllowN(time.Now(), req.Variations-1) {
+				c.JSON(http.StatusTooManyRequests, gin.H{
+					"error":       "Rate limit exceeded: each variation counts as a generation",
+					"retry_after": "Please try again later",
+				})
+				return
+			}
+		}
+	}
+
+	nudges := cuisineNudges
+	if req.CuisinePreference != "" {
+		nudges = techniqueNudges
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"variations": req.Variations,
+		"provider":   h.llm.Name(),
+	}).Info("Calling LLM provider for recipe variations")
+
+	ctx := c.Request.Context()
+	variations := make([]RecipeVariation, req.Variations)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(variationWorkers, req.Variations) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				variations[i] = h.generateVariation(ctx, req, prompt, pantry, i, nudges[i])
+			}
+		}()
+	}
+	for i := range variations {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, variation := range variations {
+		if variation.Error != "" {
+			failed++
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"variations": len(variations),
+		"failed":     failed,
+		"ip":         c.ClientIP(),
+	}).Info("Recipe variations generated")
+
+	status := http.StatusOK
+	if failed == len(variations) {
+		status = http.StatusInternalServerError
+	}
+	c.JSON(status, gin.H{
+		"variations": variations,
+		"succeeded":  len(variations) - failed,
+		"failed":     failed,
+	})
+}
+
+func (h *Handler) generateVariation(ctx context.Context, req RecipeRequest, prompt string, pantry []models.PantryItem, i int, nudge string) RecipeVariation {
+	variation := RecipeVariation{Variation: i + 1, Nudge: nudge}
+
+	prompt = fmt.Sprintf("%s\n\n%s Make this recipe clearly different from the usual take on these ingredients.", prompt, nudge)
+	recipe, err := h.generateStructuredRecipe(ctx, llm.UserPrompt(prompt), req.SuggestTags)
+	if err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"provider":  h.llm.Name(),
+			"model":     h.llm.Model(),
+			"variation": variation.Variation,
+		}).Error("Failed to generate recipe variation")
+		variation.Error = fmt.Sprintf("Failed to generate recipe: %v", err)
+		return variation
+	}
+
+	recipeData := newRecipeData(req, recipe.Render())
+	recipeData.Structured = recipe
+	if req.UsePantry {
+		recipeData.Pantry = pantryUsage(recipe, pantry, req.Ingredients)
+	}
+	variation.RecipeData = &recipeData
+	return variation
+}
@@ -0,0 +1,224 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type Anthropic struct {
+	client  *http.Client
+	baseURL string
+	version string
+	apiKey  string
+	model   string
+	timeout time.Duration
+}
+
+type anthropicRequest struct {
+	Model       string               `json:"model"`
+	MaxTokens   int                  `json:"max_tokens"`
+	Temperature float64              `json:"temperature"`
+	Messages    []Message            `json:"messages"`
+	Stream      bool                 `json:"stream,omitempty"`
+	Tools       []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Text  string          `json:"text"`
+		Type  string          `json:"type"`
+		Name  string          `json:"name"`
+		Input json.RawMessage `json:"input"`
+	} `json:"content"`
+}
+
+type anthropicStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func NewAnthropic(client *http.Client, baseURL, version, apiKey, model string, timeout time.Duration) *Anthropic {
+	return &Anthropic{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		version: version,
+		apiKey:  apiKey,
+		model:   model,
+		timeout: timeout,
+	}
+}
+
+func (a *Anthropic) Name() string {
+	return "anthropic"
+}
+
+func (a *Anthropic) Model() string {
+	return a.model
+}
+
+func (a *Anthropic) CompleteJSON(ctx context.Context, req Request, tool Tool) (json.RawMessage, error) {
+	ctx, cancel := withTimeout(ctx, a.timeout)
+	defer cancel()
+
+	reqBody := a.newRequest(req)
+	reqBody.Tools = []anthropicTool{{
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: tool.Schema,
+	}}
+	reqBody.ToolChoice = &anthropicToolChoice{Type: "tool", Name: tool.Name}
+
+	resp, err := a.post(ctx, reqBody, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var anthropicResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, block := range anthropicResp.Content {
+		if block.Type == "tool_use" && block.Name == tool.Name {
+			return block.Input, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no %s tool call in response", tool.Name)
+}
+
+func (a *Anthropic) Stream(ctx context.Context, req Request, onDelta func(string)) (string, error) {
+	resp, err := a.post(ctx, a.newRequest(req), true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	err = readServerSentEvents(resp.Body, func(data string) (bool, error) {
+		var event anthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return false, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				text.WriteString(event.Delta.Text)
+				onDelta(event.Delta.Text)
+			}
+		case "error":
+			return false, fmt.Errorf("stream error (%s): %s", event.Error.Type, event.Error.Message)
+		case "message_stop":
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+
+	return text.String(), nil
+}
+
+func (a *Anthropic) newRequest(req Request) anthropicRequest {
+	return anthropicRequest{
+		Model:       a.model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Messages:    req.Messages,
+	}
+}
+
+func (a *Anthropic) post(ctx context.Context, reqBody anthropicRequest, stream bool) (*http.Response, error) {
+	reqBody.Stream = stream
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", a.baseURL+"/v1/messages", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("x-api-key", a.apiKey)
+	httpReq.Header.Set("anthropic-version", a.version)
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := a.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
+
+// readServerSentEvents calls handle with the payload of every data line until
+// handle reports the stream is finished or the body is exhausted.
+func readServerSentEvents(body io.Reader, handle func(data string) (bool, error)) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		finished, err := handle(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		if err != nil {
+			return err
+		}
+		if finished {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	return fmt.Errorf("stream ended unexpectedly")
+}
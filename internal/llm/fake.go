@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"recipe-ai/internal/structured"
+)
+
+var (
+	fakeIngredientsPattern = regexp.MustCompile(`(?i)following ingredients:\s*(.+)`)
+	fakeServingsPattern    = regexp.MustCompile(`(?i)serving size:\s*(\d+)`)
+)
+
+// Fake returns a canned recipe derived only from the prompt, so the app can run
+// offline and produce the same output for the same request every time.
+type Fake struct{}
+
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+func (f *Fake) Model() string {
+	return "fake"
+}
+
+func (f *Fake) CompleteJSON(ctx context.Context, req Request, tool Tool) (json.RawMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if tool.Name != structured.ToolName {
+		return nil, fmt.Errorf("fake provider has no canned output for tool %q", tool.Name)
+	}
+	recipe := fakeRecipe(req)
+	if properties, ok := tool.Schema["properties"].(map[string]interface{}); ok && properties["tags"] != nil {
+		recipe.Tags = []string{"weeknight", "one-pan"}
+	}
+	return json.Marshal(recipe)
+}
+
+func (f *Fake) Stream(ctx context.Context, req Request, onDelta func(string)) (string, error) {
+	text := fakeRecipe(req).Render()
+	for _, chunk := range strings.SplitAfter(text, " ") {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		onDelta(chunk)
+	}
+	return text, nil
+}
+
+func fakeRecipe(req Request) *structured.Recipe {
+	prompt := fakePrompt(req)
+	ingredients := fakeIngredients(prompt)
+
+	servings := 4
+	if match := fakeServingsPattern.FindStringSubmatch(prompt); match != nil {
+		fmt.Sscanf(match[1], "%d", &servings)
+	}
+
+	recipe := &structured.Recipe{
+		Name:        fmt.Sprintf("Simple %s Skillet", capitalize(ingredients[0])),
+		Description: "A quick one-pan dish built from what you have on hand.",
+		PrepTime:    "10 minutes",
+		CookTime:    "20 minutes",
+		Servings:    servings,
+		Steps: []string{
+			"Heat the olive oil in a large skillet over medium heat.",
+			fmt.Sprintf("Add the %s and cook, stirring often, for 15 minutes.", strings.Join(ingredients, ", ")),
+			"Season with salt and serve warm.",
+		},
+		Nutrition: structured.Nutrition{
+			Calories:      "350 kcal",
+			Protein:       "12 g",
+			Carbohydrates: "30 g",
+			Fat:           "18 g",
+		},
+		Tips: []string{"Swap in whatever vegetables you have on hand."},
+	}
+
+	for _, ingredient := range ingredients {
+		recipe.Ingredients = append(recipe.Ingredients, structured.Ingredient{Quantity: "1", Unit: "cup", Item: ingredient})
+	}
+	recipe.Ingredients = append(recipe.Ingredients,
+		structured.Ingredient{Quantity: "1", Unit: "tbsp", Item: "olive oil"},
+		structured.Ingredient{Quantity: "1/2", Unit: "tsp", Item: "salt"},
+	)
+
+	return recipe
+}
+
+// fakePrompt returns the latest user message that asks for a recipe, so a
+// follow-up such as "make it spicier" still reuses the original ingredients.
+func fakePrompt(req Request) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" && fakeIngredientsPattern.MatchString(req.Messages[i].Content) {
+			return req.Messages[i].Content
+		}
+	}
+	return lastUserMessage(req)
+}
+
+func lastUserMessage(req Request) string {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			return req.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+func fakeIngredients(prompt string) []string {
+	var ingredients []string
+	if match := fakeIngredientsPattern.FindStringSubmatch(prompt); match != nil {
+		for _, ingredient := range strings.Split(match[1], ",") {
+			if ingredient = strings.TrimSpace(ingredient); ingredient != "" {
+				ingredients = append(ingredients, strings.ToLower(ingredient))
+			}
+		}
+	}
+	if len(ingredients) == 0 {
+		ingredients = []string{"vegetable"}
+	}
+	return ingredients
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"recipe-ai/internal/config"
+)
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type Request struct {
+	Messages    []Message
+	MaxTokens   int
+	Temperature float64
+}
+
+// Tool describes a JSON schema the model must fill in. Providers force the
+// model to call it, so the reply is always a single JSON object.
+type Tool struct {
+	Name        string
+	Description string
+	Schema      map[string]interface{}
+}
+
+// Provider is implemented by every backend that can turn a conversation into
+// a recipe. CompleteJSON returns the arguments of a forced call to tool;
+// Stream calls onDelta for each chunk of text as it arrives and returns the
+// full text once the model has finished.
+type Provider interface {
+	Name() string
+	Model() string
+	CompleteJSON(ctx context.Context, req Request, tool Tool) (json.RawMessage, error)
+	Stream(ctx context.Context, req Request, onDelta func(string)) (string, error)
+}
+
+func New(cfg *config.Config) (Provider, error) {
+	// Streaming responses can run longer than any sensible client timeout, so
+	// the shared client has none and each call bounds itself with a context.
+	client := &http.Client{}
+
+	switch cfg.LLMProvider {
+	case "anthropic":
+		if cfg.AnthropicAPIKey == "" {
+			return nil, fmt.Errorf("ANTHROPIC_API_KEY is required for the anthropic provider")
+		}
+		return NewAnthropic(client, cfg.AnthropicBaseURL, cfg.AnthropicVersion, cfg.AnthropicAPIKey, cfg.ClaudeModel, cfg.LLMTimeout), nil
+	case "openai":
+		return NewOpenAI(client, cfg.OpenAIBaseURL, cfg.OpenAIAPIKey, cfg.OpenAIModel, cfg.LLMTimeout), nil
+	case "fake":
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.LLMProvider)
+	}
+}
+
+func UserPrompt(prompt string) Request {
+	return Conversation([]Message{
+		{
+			Role:    "user",
+			Content: prompt,
+		},
+	})
+}
+
+// Conversation builds a request from earlier turns, which alternate between
+// user and assistant and end with the user's latest message.
+func Conversation(messages []Message) Request {
+	return Request{
+		MaxTokens:   2000,
+		Temperature: 0.7,
+		Messages:    messages,
+	}
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,200 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAI talks to any server implementing the OpenAI chat completions API,
+// which includes local Ollama and vLLM deployments.
+type OpenAI struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+	timeout time.Duration
+}
+
+type openAIRequest struct {
+	Model       string            `json:"model"`
+	MaxTokens   int               `json:"max_tokens"`
+	Temperature float64           `json:"temperature"`
+	Messages    []Message         `json:"messages"`
+	Stream      bool              `json:"stream,omitempty"`
+	Tools       []openAITool      `json:"tools,omitempty"`
+	ToolChoice  *openAIToolChoice `json:"tool_choice,omitempty"`
+}
+
+type openAIFunction struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type openAITool struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIToolChoice struct {
+	Type     string         `json:"type"`
+	Function openAIFunction `json:"function"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+func NewOpenAI(client *http.Client, baseURL, apiKey, model string, timeout time.Duration) *OpenAI {
+	return &OpenAI{
+		client:  client,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		model:   model,
+		timeout: timeout,
+	}
+}
+
+func (o *OpenAI) Name() string {
+	return "openai"
+}
+
+func (o *OpenAI) Model() string {
+	return o.model
+}
+
+func (o *OpenAI) CompleteJSON(ctx context.Context, req Request, tool Tool) (json.RawMessage, error) {
+	ctx, cancel := withTimeout(ctx, o.timeout)
+	defer cancel()
+
+	function := openAIFunction{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Parameters:  tool.Schema,
+	}
+	reqBody := o.newRequest(req)
+	reqBody.Tools = []openAITool{{Type: "function", Function: function}}
+	reqBody.ToolChoice = &openAIToolChoice{Type: "function", Function: openAIFunction{Name: tool.Name}}
+
+	resp, err := o.post(ctx, reqBody, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var openAIResp openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openAIResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	for _, choice := range openAIResp.Choices {
+		for _, call := range choice.Message.ToolCalls {
+			if call.Function.Name == tool.Name {
+				return json.RawMessage(call.Function.Arguments), nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("no %s tool call in response", tool.Name)
+}
+
+func (o *OpenAI) Stream(ctx context.Context, req Request, onDelta func(string)) (string, error) {
+	resp, err := o.post(ctx, o.newRequest(req), true)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var text strings.Builder
+	err = readServerSentEvents(resp.Body, func(data string) (bool, error) {
+		if data == "[DONE]" {
+			return true, nil
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return false, fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				text.WriteString(choice.Delta.Content)
+				onDelta(choice.Delta.Content)
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if text.Len() == 0 {
+		return "", fmt.Errorf("no content in response")
+	}
+
+	return text.String(), nil
+}
+
+func (o *OpenAI) newRequest(req Request) openAIRequest {
+	return openAIRequest{
+		Model:       o.model,
+		MaxTokens:   req.MaxTokens,
+		Temperature: req.Temperature,
+		Messages:    req.Messages,
+	}
+}
+
+func (o *OpenAI) post(ctx context.Context, reqBody openAIRequest, stream bool) (*http.Response, error) {
+	reqBody.Stream = stream
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", o.baseURL+"/chat/completions", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpReq.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API request failed with status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return resp, nil
+}
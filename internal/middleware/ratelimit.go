@@ -1,10 +1,13 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
+	"recipe-ai/internal/models"
+
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -52,20 +55,53 @@ func (i *IPRateLimiter) GetLimiter(ip string) *rate.Limiter {
 	return limiter
 }
 
+// GetTokenLimiter buckets requests per API token instead of per IP. A token's
+// own rate limit (requests per minute) applies when it is stricter.
+func (i *IPRateLimiter) GetTokenLimiter(token *models.APIToken) *rate.Limiter {
+	key := fmt.Sprintf("token:%d", token.ID)
+
+	r, b := i.r, i.b
+	if token.RateLimit > 0 {
+		if tokenRate := rate.Limit(float64(token.RateLimit) / 60); tokenRate < r {
+			r = tokenRate
+			b = min(b, token.RateLimit)
+		}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	limiter, exists := i.ips[key]
+	if !exists {
+		limiter = rate.NewLimiter(r, b)
+		i.ips[key] = limiter
+	}
+
+	return limiter
+}
+
 func RateLimitMiddleware(limiter *IPRateLimiter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		ip := c.ClientIP()
-		
-		l := limiter.GetLimiter(ip)
+
+		var l *rate.Limiter
+		if identity := currentIdentity(c); identity != nil && identity.Token != nil {
+			l = limiter.GetTokenLimiter(identity.Token)
+		} else {
+			l = limiter.GetLimiter(ip)
+		}
 		if !l.Allow() {
 			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error": "Rate limit exceeded",
+				"error":       "Rate limit exceeded",
 				"retry_after": "Please try again later",
 			})
 			c.Abort()
 			return
 		}
 
+		// Handlers whose requests cost more than one unit, such as
+		// generating several recipe variations, take the rest from here.
+		c.Set("rate_limiter", l)
 		c.Next()
 	}
 }
@@ -78,4 +114,9 @@ func APIRateLimitMiddleware() gin.HandlerFunc {
 func GenerateRateLimitMiddleware() gin.HandlerFunc {
 	limiter := NewIPRateLimiter(rate.Every(time.Minute/5), 2) // 5 requests per minute
 	return RateLimitMiddleware(limiter)
-}
\ No newline at end of file
+}
+
+func AuthRateLimitMiddleware() gin.HandlerFunc {
+	limiter := NewIPRateLimiter(rate.Every(time.Minute/10), 5) // 10 attempts per minute
+	return RateLimitMiddleware(limiter)
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// Collection is a user's named, ordered group of recipes, such as a cookbook.
+// A recipe can belong to any number of collections.
+type Collection struct {
+	ID            uint      `json:"id" gorm:"primary_key"`
+	UserID        uint      `json:"-" gorm:"not null;uniqueIndex:idx_collections_user_name"`
+	Name          string    `json:"name" gorm:"not null;size:100;uniqueIndex:idx_collections_user_name"`
+	Description   string    `json:"description" gorm:"not null;type:text;default:''"`
+	CoverRecipeID *uint     `json:"cover_recipe_id" gorm:"index"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	User        *User   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	CoverRecipe *Recipe `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+}
+
+func (Collection) TableName() string {
+	return "collections"
+}
+
+// CollectionRecipe places a recipe in a collection. Positions start at 0 and
+// are kept contiguous.
+type CollectionRecipe struct {
+	CollectionID uint      `json:"collection_id" gorm:"primaryKey;autoIncrement:false"`
+	RecipeID     uint      `json:"recipe_id" gorm:"primaryKey;autoIncrement:false;index"`
+	Position     int       `json:"position" gorm:"not null"`
+	CreatedAt    time.Time `json:"added_at"`
+
+	Collection *Collection `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Recipe     *Recipe     `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (CollectionRecipe) TableName() string {
+	return "collection_recipes"
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Conversation is a chat that refines a generated recipe. The whole message
+// history is sent to the model on every turn. RecipeID links the conversation
+// to the saved recipe it started from or was last saved to.
+type Conversation struct {
+	ID                  uint      `json:"id" gorm:"primary_key"`
+	UserID              uint      `json:"-" gorm:"not null;index"`
+	RecipeID            *uint     `json:"recipe_id" gorm:"index"`
+	IngredientsUsed     string    `json:"ingredients_used" gorm:"not null;type:text"`
+	DietaryRestrictions string    `json:"dietary_restrictions" gorm:"not null;size:100;default:''"`
+	CuisinePreference   string    `json:"cuisine_preference" gorm:"not null;size:100;default:''"`
+	ServingSize         int       `json:"serving_size" gorm:"not null;default:4"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+
+	Messages []ConversationMessage `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	User     *User                 `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Recipe   *Recipe               `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+}
+
+func (Conversation) TableName() string {
+	return "conversations"
+}
+
+// ConversationMessage is one turn of a conversation. Positions start at 0,
+// which is the original recipe request, and alternate between user and
+// assistant. Assistant turns keep the structured recipe as JSON alongside its
+// rendered text.
+type ConversationMessage struct {
+	ID             uint      `json:"-" gorm:"primary_key"`
+	ConversationID uint      `json:"-" gorm:"not null;uniqueIndex:idx_conversation_messages_position"`
+	Position       int       `json:"position" gorm:"not null;uniqueIndex:idx_conversation_messages_position"`
+	Role           string    `json:"role" gorm:"not null;size:20"`
+	Content        string    `json:"content" gorm:"not null;type:text"`
+	Structured     string    `json:"-" gorm:"not null;type:text;default:''"`
+	CreatedAt      time.Time `json:"created_at"`
+
+	Conversation *Conversation `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (ConversationMessage) TableName() string {
+	return "conversation_messages"
+}
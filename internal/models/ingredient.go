@@ -0,0 +1,103 @@
+package models
+
+import (
+	"recipe-ai/internal/structured"
+
+	"gorm.io/gorm"
+)
+
+type RecipeIngredient struct {
+	ID       uint   `json:"id" gorm:"primary_key"`
+	RecipeID uint   `json:"-" gorm:"not null;index"`
+	Position int    `json:"position" gorm:"not null"`
+	Quantity string `json:"quantity" gorm:"size:50"`
+	Unit     string `json:"unit" gorm:"size:50"`
+	Item     string `json:"item" gorm:"not null;type:text"`
+	Note     string `json:"note" gorm:"type:text"`
+}
+
+func (RecipeIngredient) TableName() string {
+	return "recipe_ingredients"
+}
+
+type RecipeStep struct {
+	ID          uint   `json:"id" gorm:"primary_key"`
+	RecipeID    uint   `json:"-" gorm:"not null;index"`
+	Position    int    `json:"position" gorm:"not null"`
+	Instruction string `json:"instruction" gorm:"not null;type:text"`
+}
+
+func (RecipeStep) TableName() string {
+	return "recipe_steps"
+}
+
+func (i RecipeIngredient) Structured() structured.Ingredient {
+	return structured.Ingredient{
+		Quantity: i.Quantity,
+		Unit:     i.Unit,
+		Item:     i.Item,
+		Note:     i.Note,
+	}
+}
+
+func NewRecipeIngredients(ingredients []structured.Ingredient) []RecipeIngredient {
+	result := make([]RecipeIngredient, 0, len(ingredients))
+	for i, ingredient := range ingredients {
+		result = append(result, RecipeIngredient{
+			Position: i + 1,
+			Quantity: ingredient.Quantity,
+			Unit:     ingredient.Unit,
+			Item:     ingredient.Item,
+			Note:     ingredient.Note,
+		})
+	}
+	return result
+}
+
+func NewRecipeSteps(steps []string) []RecipeStep {
+	result := make([]RecipeStep, 0, len(steps))
+	for i, step := range steps {
+		result = append(result, RecipeStep{
+			Position:    i + 1,
+			Instruction: step,
+		})
+	}
+	return result
+}
+
+// ReplaceRecipeStructure swaps the normalized ingredient and step rows of a
+// recipe for freshly parsed ones. Call it inside a transaction.
+func ReplaceRecipeStructure(tx *gorm.DB, recipeID uint, ingredients []structured.Ingredient, steps []string) error {
+	if err := tx.Where("recipe_id = ?", recipeID).Delete(&RecipeIngredient{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("recipe_id = ?", recipeID).Delete(&RecipeStep{}).Error; err != nil {
+		return err
+	}
+
+	rows := NewRecipeIngredients(ingredients)
+	for i := range rows {
+		rows[i].RecipeID = recipeID
+	}
+	if len(rows) > 0 {
+		if err := tx.Create(&rows).Error; err != nil {
+			return err
+		}
+	}
+
+	stepRows := NewRecipeSteps(steps)
+	for i := range stepRows {
+		stepRows[i].RecipeID = recipeID
+	}
+	if len(stepRows) > 0 {
+		if err := tx.Create(&stepRows).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func OrderByPosition(db *gorm.DB) *gorm.DB {
+	return db.Order("position ASC")
+}
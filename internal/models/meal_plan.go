@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// MealSlots are the meals of a day, in the order they are eaten.
+var MealSlots = []string{"breakfast", "lunch", "dinner", "snack"}
+
+// MealPlanEntry puts a saved recipe on the calendar. A slot can hold several
+// entries, such as a main and a side for dinner.
+type MealPlanEntry struct {
+	ID               uint      `json:"id" gorm:"primary_key"`
+	UserID           uint      `json:"-" gorm:"not null;index:idx_meal_plan_entries_user_date"`
+	RecipeID         uint      `json:"recipe_id" gorm:"not null;index"`
+	Date             Date      `json:"date" gorm:"not null;index:idx_meal_plan_entries_user_date"`
+	Slot             string    `json:"slot" gorm:"not null;size:20"`
+	ServingsOverride *int      `json:"servings_override"`
+	Note             string    `json:"note" gorm:"not null;size:200;default:''"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+
+	Recipe *Recipe `json:"recipe,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	User   *User   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (MealPlanEntry) TableName() string {
+	return "meal_plan_entries"
+}
+
+// EffectiveServings is the override if one is set and otherwise the recipe's
+// own serving size, which needs Recipe to be loaded.
+func (e MealPlanEntry) EffectiveServings() int {
+	if e.ServingsOverride != nil {
+		return *e.ServingsOverride
+	}
+	if e.Recipe != nil && e.Recipe.ServingSize > 0 {
+		return e.Recipe.ServingSize
+	}
+	return 4
+}
+
+// OrderBySlot orders meal plan entries by date and then by meal.
+func OrderBySlot(db *gorm.DB) *gorm.DB {
+	return db.Order("date, array_position(ARRAY['breakfast','lunch','dinner','snack']::varchar[], slot), id")
+}
+
+const DateLayout = "2006-01-02"
+
+// Date is a calendar day without a time or time zone, stored as a SQL date
+// and written as YYYY-MM-DD in JSON.
+type Date struct {
+	time.Time
+}
+
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// Today is the current date in the server's time zone.
+func Today() Date {
+	now := time.Now()
+	return NewDate(now.Year(), now.Month(), now.Day())
+}
+
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return Date{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return Date{t}, nil
+}
+
+func (d Date) String() string {
+	return d.Format(DateLayout)
+}
+
+func (d Date) AddDays(days int) Date {
+	return Date{d.AddDate(0, 0, days)}
+}
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("date must be a YYYY-MM-DD string")
+	}
+	parsed, err := ParseDate(string(data[1 : len(data)-1]))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = NewDate(v.Year(), v.Month(), v.Day())
+		return nil
+	case string:
+		parsed, err := ParseDate(v[:min(len(v), len(DateLayout))])
+		*d = parsed
+		return err
+	case []byte:
+		return d.Scan(string(v))
+	default:
+		return fmt.Errorf("cannot scan %T into Date", value)
+	}
+}
+
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+func (Date) GormDataType() string {
+	return "date"
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PantryItem is something a user has at home. The same item can be listed
+// more than once, such as two cartons of milk with different expiry dates.
+type PantryItem struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	UserID    uint      `json:"-" gorm:"not null;index"`
+	Item      string    `json:"item" gorm:"not null;size:200"`
+	Quantity  string    `json:"quantity" gorm:"not null;size:50;default:''"`
+	Unit      string    `json:"unit" gorm:"not null;size:50;default:''"`
+	ExpiresOn *Date     `json:"expires_on"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	User *User `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (PantryItem) TableName() string {
+	return "pantry_items"
+}
+
+// DaysLeft is the number of days until the item expires, negative once it
+// has, or nil when it has no expiry date.
+func (p PantryItem) DaysLeft(today Date) *int {
+	if p.ExpiresOn == nil {
+		return nil
+	}
+	days := int(p.ExpiresOn.Sub(today.Time).Hours() / 24)
+	return &days
+}
+
+// OrderByExpiry orders pantry items soonest expiring first, with items that
+// do not expire last.
+func OrderByExpiry(db *gorm.DB) *gorm.DB {
+	return db.Order("expires_on ASC NULLS LAST, lower(item), id")
+}
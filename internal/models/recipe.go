@@ -9,22 +9,37 @@ import (
 )
 
 type Recipe struct {
-	ID                  uint      `json:"id" gorm:"primary_key"`
-	Title               string    `json:"title" gorm:"not null;size:200"`
-	RecipeContent       string    `json:"recipe" gorm:"not null;type:text"`
-	IngredientsUsed     string    `json:"ingredients_used" gorm:"not null;type:text"`
-	DietaryRestrictions *string   `json:"dietary_restrictions" gorm:"size:100"`
-	CuisinePreference   *string   `json:"cuisine_preference" gorm:"size:100"`
-	ServingSize         int       `json:"serving_size" gorm:"default:4"`
-	Rating              *int      `json:"rating" gorm:"check:rating >= 1 AND rating <= 5"`
-	CreatedAt           time.Time `json:"timestamp"`
-	UpdatedAt           time.Time `json:"-"`
+	ID                  uint           `json:"id" gorm:"primary_key"`
+	UserID              *uint          `json:"user_id,omitempty" gorm:"index"`
+	Title               string         `json:"title" gorm:"not null;size:200"`
+	RecipeContent       string         `json:"recipe" gorm:"not null;type:text"`
+	IngredientsUsed     string         `json:"ingredients_used" gorm:"not null;type:text"`
+	DietaryRestrictions *string        `json:"dietary_restrictions" gorm:"size:100"`
+	CuisinePreference   *string        `json:"cuisine_preference" gorm:"size:100"`
+	ServingSize         int            `json:"serving_size" gorm:"default:4"`
+	Rating              *int           `json:"rating" gorm:"check:rating >= 1 AND rating <= 5"`
+	CreatedAt           time.Time      `json:"timestamp"`
+	UpdatedAt           time.Time      `json:"-"`
+	DeletedAt           gorm.DeletedAt `json:"-" gorm:"index"`
+
+	Ingredients []RecipeIngredient `json:"ingredients,omitempty" gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+	Steps       []RecipeStep       `json:"steps,omitempty" gorm:"foreignKey:RecipeID;constraint:OnDelete:CASCADE"`
+	Tags        []Tag              `json:"tags,omitempty" gorm:"many2many:recipe_tags;joinForeignKey:RecipeID;joinReferences:TagID;constraint:OnDelete:CASCADE"`
+	User        *User              `json:"-" gorm:"constraint:OnDelete:CASCADE"`
 }
 
 func (Recipe) TableName() string {
 	return "recipes"
 }
 
+// PurgeTrashedRecipes permanently deletes recipes that were moved to the trash
+// before cutoff, along with their ingredients, steps, revisions and share
+// links.
+func PurgeTrashedRecipes(db *gorm.DB, cutoff time.Time) (int64, error) {
+	result := db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&Recipe{})
+	return result.RowsAffected, result.Error
+}
+
 func (r *Recipe) BeforeCreate(tx *gorm.DB) error {
 	if r.Title == "" {
 		r.Title = ExtractTitleFromContent(r.RecipeContent)
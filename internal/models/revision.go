@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RecipeRevision is a snapshot of a recipe's editable fields. Revision 1 is
+// the recipe as first saved; every update and restore appends a new one.
+type RecipeRevision struct {
+	ID                  uint      `json:"id" gorm:"primary_key"`
+	RecipeID            uint      `json:"recipe_id" gorm:"not null;uniqueIndex:idx_recipe_revisions_recipe_revision"`
+	Revision            int       `json:"revision" gorm:"not null;uniqueIndex:idx_recipe_revisions_recipe_revision"`
+	Title               string    `json:"title" gorm:"not null;size:200"`
+	RecipeContent       string    `json:"recipe" gorm:"not null;type:text"`
+	IngredientsUsed     string    `json:"ingredients_used" gorm:"not null;type:text"`
+	DietaryRestrictions *string   `json:"dietary_restrictions" gorm:"size:100"`
+	CuisinePreference   *string   `json:"cuisine_preference" gorm:"size:100"`
+	ServingSize         int       `json:"serving_size"`
+	AuthorID            *uint     `json:"author_id" gorm:"index"`
+	CreatedAt           time.Time `json:"timestamp"`
+
+	Recipe *Recipe `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	Author *User   `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+}
+
+func (RecipeRevision) TableName() string {
+	return "recipe_revisions"
+}
+
+// RecordRevision snapshots the current state of a recipe as its next
+// revision. Call it inside the transaction that changed the recipe.
+func RecordRevision(tx *gorm.DB, recipeID uint, authorID *uint) (*RecipeRevision, error) {
+	// Lock the recipe row until the transaction ends, so that concurrent
+	// updates number their revisions one after the other instead of both
+	// taking the same next number.
+	var recipe Recipe
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&recipe, recipeID).Error; err != nil {
+		return nil, err
+	}
+
+	var latest int
+	err := tx.Model(&RecipeRevision{}).Where("recipe_id = ?", recipeID).
+		Select("COALESCE(MAX(revision), 0)").Scan(&latest).Error
+	if err != nil {
+		return nil, err
+	}
+
+	revision := RecipeRevision{
+		RecipeID:            recipe.ID,
+		Revision:            latest + 1,
+		Title:               recipe.Title,
+		RecipeContent:       recipe.RecipeContent,
+		IngredientsUsed:     recipe.IngredientsUsed,
+		DietaryRestrictions: recipe.DietaryRestrictions,
+		CuisinePreference:   recipe.CuisinePreference,
+		ServingSize:         recipe.ServingSize,
+		AuthorID:            authorID,
+	}
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// RecipeShare is a public read-only link to a recipe. Anyone with the token
+// can view the recipe until the link expires or is revoked.
+type RecipeShare struct {
+	ID           uint       `json:"id" gorm:"primary_key"`
+	RecipeID     uint       `json:"recipe_id" gorm:"not null;index"`
+	UserID       uint       `json:"-" gorm:"not null;index"`
+	Token        string     `json:"token" gorm:"not null;size:64;uniqueIndex"`
+	ViewCount    int        `json:"view_count" gorm:"not null;default:0"`
+	LastViewedAt *time.Time `json:"last_viewed_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+
+	Recipe *Recipe `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+	User   *User   `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (RecipeShare) TableName() string {
+	return "recipe_shares"
+}
+
+// Active reports whether the link can still be used at the given time.
+func (s RecipeShare) Active(now time.Time) bool {
+	return s.RevokedAt == nil && (s.ExpiresAt == nil || now.Before(*s.ExpiresAt))
+}
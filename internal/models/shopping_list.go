@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ShoppingList is a saved, merged list of what to buy for a set of recipes.
+// Its items are fixed when the list is made; only their checked state changes.
+type ShoppingList struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	UserID    uint      `json:"-" gorm:"not null;index"`
+	Name      string    `json:"name" gorm:"not null;size:100"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	Items []ShoppingListItem `json:"items,omitempty" gorm:"constraint:OnDelete:CASCADE"`
+	User  *User              `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (ShoppingList) TableName() string {
+	return "shopping_lists"
+}
+
+// ShoppingListItem is one line of a shopping list. Items are positioned in
+// aisle order starting at 0.
+type ShoppingListItem struct {
+	ID             uint   `json:"id" gorm:"primary_key"`
+	ShoppingListID uint   `json:"-" gorm:"not null;index"`
+	Position       int    `json:"position" gorm:"not null"`
+	Aisle          string `json:"aisle" gorm:"not null;size:50"`
+	Item           string `json:"item" gorm:"not null;type:text"`
+	Quantity       string `json:"quantity" gorm:"size:50"`
+	Unit           string `json:"unit" gorm:"size:50"`
+	Checked        bool   `json:"checked" gorm:"not null;default:false"`
+}
+
+func (ShoppingListItem) TableName() string {
+	return "shopping_list_items"
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const MaxTagLength = 50
+
+// Tag is a free-form label such as "weeknight" that a user attaches to their
+// recipes. Names are stored normalized, so each user has one tag per name.
+type Tag struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	UserID    uint      `json:"-" gorm:"not null;uniqueIndex:idx_tags_user_name"`
+	Name      string    `json:"name" gorm:"not null;size:50;uniqueIndex:idx_tags_user_name"`
+	CreatedAt time.Time `json:"-"`
+
+	User *User `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+var (
+	tagSeparatorPattern = regexp.MustCompile(`[\s_]+`)
+	tagInvalidPattern   = regexp.MustCompile(`[^\pL\pN-]+`)
+	tagHyphensPattern   = regexp.MustCompile(`-{2,}`)
+)
+
+// NormalizeTag lower-cases a tag name and joins its words with hyphens, so
+// "Freezer Friendly" and "freezer-friendly" are the same tag.
+func NormalizeTag(name string) (string, error) {
+	tag := strings.ToLower(strings.TrimSpace(name))
+	tag = tagSeparatorPattern.ReplaceAllString(tag, "-")
+	tag = tagInvalidPattern.ReplaceAllString(tag, "")
+	tag = strings.Trim(tagHyphensPattern.ReplaceAllString(tag, "-"), "-")
+
+	if tag == "" {
+		return "", fmt.Errorf("tag %q must contain a letter or digit", name)
+	}
+	if len([]rune(tag)) > MaxTagLength {
+		return "", fmt.Errorf("tag %q is longer than %d characters", name, MaxTagLength)
+	}
+	return tag, nil
+}
+
+// AddRecipeTags attaches tags, which must already be normalized, to a recipe,
+// creating any the user does not have yet.
+func AddRecipeTags(tx *gorm.DB, userID, recipeID uint, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+
+	tags := make([]Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, Tag{UserID: userID, Name: name})
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+
+	return tx.Exec(`INSERT INTO recipe_tags (recipe_id, tag_id)
+		SELECT ?, id FROM tags WHERE user_id = ? AND name IN ?
+		ON CONFLICT DO NOTHING`, recipeID, userID, names).Error
+}
+
+// PruneTags deletes a user's tags that are no longer on any recipe.
+func PruneTags(tx *gorm.DB, userID uint) error {
+	return tx.Where("user_id = ? AND NOT EXISTS (SELECT 1 FROM recipe_tags WHERE recipe_tags.tag_id = tags.id)", userID).
+		Delete(&Tag{}).Error
+}
+
+func OrderByName(db *gorm.DB) *gorm.DB {
+	return db.Order("name ASC")
+}
@@ -0,0 +1,49 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIToken is a personal access token. Only a hash of the token is stored;
+// Prefix is kept so users can tell their tokens apart.
+type APIToken struct {
+	ID         uint       `json:"id" gorm:"primary_key"`
+	UserID     uint       `json:"-" gorm:"not null;index"`
+	Name       string     `json:"name" gorm:"not null;size:100"`
+	Prefix     string     `json:"prefix" gorm:"not null;size:20"`
+	TokenHash  string     `json:"-" gorm:"not null;size:64;uniqueIndex"`
+	Scopes     string     `json:"-" gorm:"not null;size:200"`
+	RateLimit  int        `json:"rate_limit" gorm:"not null;default:0"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	User *User `json:"-" gorm:"constraint:OnDelete:CASCADE"`
+}
+
+func (APIToken) TableName() string {
+	return "api_tokens"
+}
+
+func (t APIToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return nil
+	}
+	return strings.Split(t.Scopes, ",")
+}
+
+func (t APIToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Active reports whether the token can still be used at the given time.
+func (t APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && (t.ExpiresAt == nil || now.Before(*t.ExpiresAt))
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// User is a local account. Accounts created through single sign-on have an
+// empty PasswordHash and are linked to the identity provider by OIDCIssuer and
+// OIDCSubject.
+type User struct {
+	ID           uint      `json:"id" gorm:"primary_key"`
+	Email        string    `json:"email" gorm:"not null;size:255;uniqueIndex"`
+	PasswordHash string    `json:"-" gorm:"not null;size:255"`
+	Role         string    `json:"role" gorm:"not null;size:20;default:editor"`
+	OIDCIssuer   *string   `json:"-" gorm:"column:oidc_issuer;size:255;uniqueIndex:idx_users_oidc_identity"`
+	OIDCSubject  *string   `json:"-" gorm:"column:oidc_subject;size:255;uniqueIndex:idx_users_oidc_identity"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+func (User) TableName() string {
+	return "users"
+}
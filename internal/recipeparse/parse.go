@@ -0,0 +1,174 @@
+package recipeparse
+
+import (
+	"regexp"
+	"strings"
+
+	"recipe-ai/internal/structured"
+	"recipe-ai/internal/units"
+)
+
+type section int
+
+const (
+	sectionNone section = iota
+	sectionIngredients
+	sectionSteps
+	sectionOther
+)
+
+var (
+	listMarkerPattern = regexp.MustCompile(`(?i)^(?:[-*•‣▪]|\d+[.)]|step\s*\d+[:.)]?)\s*`)
+	markupPattern     = regexp.MustCompile(`[*_#]+`)
+	parenPattern      = regexp.MustCompile(`^\(([^)]*)\)\s*`)
+)
+
+// ParseContent pulls the ingredient list and ordered steps out of free-text
+// recipe content by following the section headers the model usually emits.
+func ParseContent(content string) ([]structured.Ingredient, []string) {
+	var ingredients []structured.Ingredient
+	var steps []string
+
+	current := sectionNone
+	for _, rawLine := range strings.Split(content, "\n") {
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			continue
+		}
+
+		// Unrecognised headers are sub-headings such as "For the sauce:" and
+		// keep the current section.
+		if header, ok := headerText(line); ok {
+			if next := classifyHeader(header); next != sectionNone {
+				current = next
+			}
+			continue
+		}
+
+		switch current {
+		case sectionIngredients:
+			if ingredient := ParseIngredientLine(line); ingredient.Item != "" {
+				ingredients = append(ingredients, ingredient)
+			}
+		case sectionSteps:
+			if step := cleanListItem(line); step != "" {
+				steps = append(steps, step)
+			}
+		}
+	}
+
+	return ingredients, steps
+}
+
+// RewriteIngredientLines replaces every line of the ingredient section of
+// free-text recipe content with rewrite(line), leaving headers and every other
+// section as they are.
+func RewriteIngredientLines(content string, rewrite func(string) string) string {
+	lines := strings.Split(content, "\n")
+
+	current := sectionNone
+	for i, rawLine := range lines {
+		line := strings.TrimSpace(rawLine)
+		if line == "" {
+			continue
+		}
+		if header, ok := headerText(line); ok {
+			if next := classifyHeader(header); next != sectionNone {
+				current = next
+			}
+			continue
+		}
+		if current == sectionIngredients {
+			lines[i] = rewrite(rawLine)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// ParseIngredientLine splits a single ingredient line such as
+// "1 1/2 cups flour, sifted" into quantity, unit, item and note.
+func ParseIngredientLine(line string) structured.Ingredient {
+	rest := cleanListItem(line)
+
+	var ingredient structured.Ingredient
+	ingredient.Quantity, rest = units.SplitQuantity(rest)
+
+	var notes []string
+	if match := parenPattern.FindStringSubmatch(rest); match != nil {
+		notes = append(notes, strings.TrimSpace(match[1]))
+		rest = rest[len(match[0]):]
+	}
+
+	if unit, remaining, ok := units.SplitUnit(rest); ok {
+		ingredient.Unit = unit
+		rest = remaining
+	}
+	rest = strings.TrimPrefix(rest, "of ")
+
+	if i := strings.Index(rest, ","); i >= 0 {
+		notes = append(notes, strings.TrimSpace(rest[i+1:]))
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "("); i >= 0 {
+		if j := strings.Index(rest[i:], ")"); j >= 0 {
+			notes = append(notes, strings.TrimSpace(rest[i+1:i+j]))
+			rest = rest[:i] + rest[i+j+1:]
+		}
+	}
+
+	ingredient.Item = strings.TrimSpace(rest)
+	ingredient.Note = strings.Join(nonEmpty(notes), "; ")
+	return ingredient
+}
+
+func headerText(line string) (string, bool) {
+	isMarkdownHeader := strings.HasPrefix(line, "#")
+	isBold := strings.HasPrefix(line, "**") && strings.HasSuffix(line, "**")
+
+	text := strings.TrimSpace(markupPattern.ReplaceAllString(line, ""))
+	endsWithColon := strings.HasSuffix(text, ":")
+	text = strings.TrimSpace(strings.TrimSuffix(text, ":"))
+
+	if text == "" || len(text) > 60 {
+		return "", false
+	}
+	if isMarkdownHeader || isBold || (endsWithColon && !listMarkerPattern.MatchString(line)) {
+		return strings.ToLower(text), true
+	}
+	return "", false
+}
+
+func classifyHeader(header string) section {
+	switch {
+	case strings.Contains(header, "ingredient"):
+		return sectionIngredients
+	case strings.Contains(header, "instruction"), strings.Contains(header, "direction"),
+		strings.Contains(header, "method"), strings.Contains(header, "steps"),
+		strings.Contains(header, "preparation"):
+		return sectionSteps
+	case strings.Contains(header, "nutrition"), strings.Contains(header, "tip"),
+		strings.Contains(header, "variation"), strings.Contains(header, "note"),
+		strings.Contains(header, "serving"), strings.Contains(header, "time"),
+		strings.Contains(header, "recipe"):
+		return sectionOther
+	}
+	return sectionNone
+}
+
+func cleanListItem(line string) string {
+	line = strings.TrimSpace(line)
+	line = listMarkerPattern.ReplaceAllString(line, "")
+	line = strings.ReplaceAll(line, "**", "")
+	return strings.TrimSpace(line)
+}
+
+func nonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,128 @@
+package recipeparse
+
+import (
+	"reflect"
+	"testing"
+
+	"recipe-ai/internal/structured"
+)
+
+func TestParseIngredientLine(t *testing.T) {
+	tests := []struct {
+		line string
+		want structured.Ingredient
+	}{
+		{"- 1 1/2 cups flour, sifted", structured.Ingredient{Quantity: "1 1/2", Unit: "cup", Item: "flour", Note: "sifted"}},
+		{"* 2 Tablespoons olive oil", structured.Ingredient{Quantity: "2", Unit: "tbsp", Item: "olive oil"}},
+		{"1. 3 cloves garlic, minced", structured.Ingredient{Quantity: "3", Unit: "clove", Item: "garlic", Note: "minced"}},
+		{"2-3 large eggs", structured.Ingredient{Quantity: "2-3", Item: "large eggs"}},
+		{"1 (14 oz) can tomatoes", structured.Ingredient{Quantity: "1", Unit: "can", Item: "tomatoes", Note: "14 oz"}},
+		{"½ cup of milk", structured.Ingredient{Quantity: "½", Unit: "cup", Item: "milk"}},
+		{"1 lb chicken breast (boneless), cubed", structured.Ingredient{Quantity: "1", Unit: "lb", Item: "chicken breast", Note: "cubed; boneless"}},
+		{"- Salt to taste", structured.Ingredient{Item: "Salt to taste"}},
+		{"- **2 cups** rice", structured.Ingredient{Quantity: "2", Unit: "cup", Item: "rice"}},
+	}
+
+	for _, tt := range tests {
+		if got := ParseIngredientLine(tt.line); got != tt.want {
+			t.Errorf("ParseIngredientLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestParseContent(t *testing.T) {
+	tests := []struct {
+		name            string
+		content         string
+		wantIngredients []structured.Ingredient
+		wantSteps       []string
+	}{
+		{
+			name: "plain headers",
+			content: `Recipe Name: Garlic Rice
+
+Ingredients:
+- 1 cup rice
+- 2 cloves garlic, minced
+
+Instructions:
+1. Rinse the rice.
+2. Cook with the garlic.
+
+Tips:
+- Use day-old rice for fried rice.`,
+			wantIngredients: []structured.Ingredient{
+				{Quantity: "1", Unit: "cup", Item: "rice"},
+				{Quantity: "2", Unit: "clove", Item: "garlic", Note: "minced"},
+			},
+			wantSteps: []string{"Rinse the rice.", "Cook with the garlic."},
+		},
+		{
+			name: "markdown headers and sub-headings",
+			content: `# Pasta
+
+## Ingredients
+**For the sauce:**
+- 2 tbsp butter
+For the pasta:
+- 200 g spaghetti
+
+**Step-by-Step Instructions**
+Step 1: Boil the pasta.
+Step 2) Melt the butter.
+
+## Nutritional Information
+- Calories: 500`,
+			wantIngredients: []structured.Ingredient{
+				{Quantity: "2", Unit: "tbsp", Item: "butter"},
+				{Quantity: "200", Unit: "g", Item: "spaghetti"},
+			},
+			wantSteps: []string{"Boil the pasta.", "Melt the butter."},
+		},
+		{
+			name:    "no sections",
+			content: "Just mix everything together and bake.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ingredients, steps := ParseContent(tt.content)
+			if !reflect.DeepEqual(ingredients, tt.wantIngredients) {
+				t.Errorf("ingredients = %+v, want %+v", ingredients, tt.wantIngredients)
+			}
+			if !reflect.DeepEqual(steps, tt.wantSteps) {
+				t.Errorf("steps = %q, want %q", steps, tt.wantSteps)
+			}
+		})
+	}
+}
+
+func TestRewriteIngredientLines(t *testing.T) {
+	content := `Recipe Name: Soup
+Servings: 2
+
+## Ingredients
+- 1 onion
+
+- 2 cups stock
+
+Instructions:
+1. Chop the onion.`
+
+	want := `Recipe Name: Soup
+Servings: 2
+
+## Ingredients
+[- 1 onion]
+
+[- 2 cups stock]
+
+Instructions:
+1. Chop the onion.`
+
+	got := RewriteIngredientLines(content, func(line string) string { return "[" + line + "]" })
+	if got != want {
+		t.Errorf("RewriteIngredientLines() =\n%s\nwant\n%s", got, want)
+	}
+}
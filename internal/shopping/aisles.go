@@ -0,0 +1,141 @@
+package shopping
+
+import (
+	"sort"
+	"strings"
+)
+
+const (
+	AisleProduce   = "Produce"
+	AisleMeat      = "Meat & Seafood"
+	AisleDairy     = "Dairy & Eggs"
+	AisleBakery    = "Bakery"
+	AislePantry    = "Pantry"
+	AisleSpices    = "Spices & Seasonings"
+	AisleFrozen    = "Frozen"
+	AisleBeverages = "Beverages"
+	AisleOther     = "Other"
+)
+
+// Aisles lists the store aisles in the order a shopping list walks them.
+var Aisles = []string{
+	AisleProduce, AisleBakery, AisleMeat, AisleDairy, AislePantry,
+	AisleSpices, AisleFrozen, AisleBeverages, AisleOther,
+}
+
+// aisleKeywords maps ingredient names to aisles. Keywords are matched as whole
+// words of the singularized ingredient name, longest first, so "coconut milk"
+// wins over "milk" and "garlic powder" over "garlic".
+var aisleKeywords = map[string]string{
+	"onion": AisleProduce, "green onion": AisleProduce, "scallion": AisleProduce,
+	"shallot": AisleProduce, "leek": AisleProduce, "garlic": AisleProduce,
+	"garlic clove": AisleProduce, "ginger": AisleProduce, "tomato": AisleProduce,
+	"potato": AisleProduce, "sweet potato": AisleProduce, "carrot": AisleProduce,
+	"celery": AisleProduce, "bell pepper": AisleProduce, "jalapeno": AisleProduce,
+	"jalapeño": AisleProduce, "chili pepper": AisleProduce, "lettuce": AisleProduce,
+	"spinach": AisleProduce, "kale": AisleProduce, "arugula": AisleProduce,
+	"cabbage": AisleProduce, "broccoli": AisleProduce, "cauliflower": AisleProduce,
+	"zucchini": AisleProduce, "cucumber": AisleProduce, "eggplant": AisleProduce,
+	"mushroom": AisleProduce, "avocado": AisleProduce, "asparagus": AisleProduce,
+	"corn": AisleProduce, "pea": AisleProduce, "green bean": AisleProduce,
+	"squash": AisleProduce, "pumpkin": AisleProduce, "beet": AisleProduce,
+	"radish": AisleProduce, "lemon": AisleProduce, "lime": AisleProduce,
+	"orange": AisleProduce, "apple": AisleProduce, "banana": AisleProduce,
+	"berry": AisleProduce, "strawberry": AisleProduce, "blueberry": AisleProduce,
+	"raspberry": AisleProduce, "grape": AisleProduce, "mango": AisleProduce,
+	"pineapple": AisleProduce, "peach": AisleProduce, "pear": AisleProduce,
+	"cilantro": AisleProduce, "parsley": AisleProduce, "basil": AisleProduce,
+	"mint": AisleProduce, "dill": AisleProduce, "chive": AisleProduce,
+
+	"bread": AisleBakery, "baguette": AisleBakery, "bun": AisleBakery,
+	"tortilla": AisleBakery, "pita": AisleBakery, "naan": AisleBakery,
+	"bagel": AisleBakery, "croissant": AisleBakery,
+
+	"chicken": AisleMeat, "beef": AisleMeat, "ground beef": AisleMeat,
+	"steak": AisleMeat, "pork": AisleMeat, "lamb": AisleMeat, "turkey": AisleMeat,
+	"duck": AisleMeat, "bacon": AisleMeat, "ham": AisleMeat, "sausage": AisleMeat,
+	"chorizo": AisleMeat, "prosciutto": AisleMeat, "fish": AisleMeat,
+	"salmon": AisleMeat, "tuna": AisleMeat, "cod": AisleMeat, "tilapia": AisleMeat,
+	"shrimp": AisleMeat, "prawn": AisleMeat, "crab": AisleMeat, "scallop": AisleMeat,
+	"mussel": AisleMeat, "clam": AisleMeat, "anchovy": AisleMeat,
+
+	"milk": AisleDairy, "buttermilk": AisleDairy, "butter": AisleDairy,
+	"cream": AisleDairy, "heavy cream": AisleDairy, "sour cream": AisleDairy,
+	"cream cheese": AisleDairy, "cheese": AisleDairy, "parmesan": AisleDairy,
+	"mozzarella": AisleDairy, "cheddar": AisleDairy, "feta": AisleDairy,
+	"ricotta": AisleDairy, "yogurt": AisleDairy, "egg": AisleDairy,
+
+	"flour": AislePantry, "sugar": AislePantry, "brown sugar": AislePantry,
+	"rice": AislePantry, "pasta": AislePantry, "spaghetti": AislePantry,
+	"noodle": AislePantry, "oat": AislePantry, "quinoa": AislePantry,
+	"couscous": AislePantry, "lentil": AislePantry, "chickpea": AislePantry,
+	"bean": AislePantry, "oil": AislePantry, "olive oil": AislePantry,
+	"vinegar": AislePantry, "soy sauce": AislePantry, "sauce": AislePantry,
+	"tomato paste": AislePantry, "tomato sauce": AislePantry, "salsa": AislePantry,
+	"ketchup": AislePantry, "mustard": AislePantry, "mayonnaise": AislePantry,
+	"honey": AislePantry, "maple syrup": AislePantry, "syrup": AislePantry,
+	"jam": AislePantry, "peanut butter": AislePantry, "tahini": AislePantry,
+	"coconut milk": AislePantry, "broth": AislePantry, "stock": AislePantry,
+	"chicken broth": AislePantry, "chicken stock": AislePantry,
+	"beef broth": AislePantry, "beef stock": AislePantry,
+	"baking soda": AislePantry, "baking powder": AislePantry, "yeast": AislePantry,
+	"cornstarch": AislePantry, "cocoa": AislePantry, "chocolate": AislePantry,
+	"chocolate chip": AislePantry, "vanilla": AislePantry, "vanilla extract": AislePantry,
+	"nut": AislePantry, "almond": AislePantry, "walnut": AislePantry,
+	"pecan": AislePantry, "peanut": AislePantry, "cashew": AislePantry,
+	"raisin": AislePantry, "breadcrumb": AislePantry, "bread crumb": AislePantry,
+	"panko": AislePantry, "canned tomato": AislePantry, "crushed tomato": AislePantry,
+
+	"salt": AisleSpices, "pepper": AisleSpices, "black pepper": AisleSpices,
+	"red pepper flake": AisleSpices, "cumin": AisleSpices, "paprika": AisleSpices,
+	"smoked paprika": AisleSpices, "cinnamon": AisleSpices, "nutmeg": AisleSpices,
+	"oregano": AisleSpices, "thyme": AisleSpices, "rosemary": AisleSpices,
+	"bay leaf": AisleSpices, "bay leaves": AisleSpices, "chili powder": AisleSpices, "curry powder": AisleSpices,
+	"garlic powder": AisleSpices, "onion powder": AisleSpices, "turmeric": AisleSpices,
+	"coriander": AisleSpices, "cayenne": AisleSpices, "ground ginger": AisleSpices,
+	"dried basil": AisleSpices, "dried parsley": AisleSpices, "seasoning": AisleSpices,
+	"italian seasoning": AisleSpices, "spice": AisleSpices, "sesame seed": AisleSpices,
+	"cream of tartar": AisleSpices,
+
+	"ice cream": AisleFrozen,
+
+	"wine": AisleBeverages, "beer": AisleBeverages, "coffee": AisleBeverages,
+	"tea": AisleBeverages, "juice": AisleBeverages, "orange juice": AisleBeverages,
+	"lemon juice": AisleProduce, "lime juice": AisleProduce,
+	"club soda": AisleBeverages, "sparkling water": AisleBeverages,
+}
+
+type aisleMatcher struct {
+	key   string
+	aisle string
+}
+
+// aisleMatchers holds the normalized aisle keywords, longest first.
+var aisleMatchers = func() []aisleMatcher {
+	matchers := make([]aisleMatcher, 0, len(aisleKeywords))
+	for key, aisle := range aisleKeywords {
+		matchers = append(matchers, aisleMatcher{key: " " + normalizeName(key) + " ", aisle: aisle})
+	}
+	sort.Slice(matchers, func(i, j int) bool {
+		if len(matchers[i].key) != len(matchers[j].key) {
+			return len(matchers[i].key) > len(matchers[j].key)
+		}
+		return matchers[i].key < matchers[j].key
+	})
+	return matchers
+}()
+
+// Aisle returns the store aisle an ingredient is usually found in, or
+// AisleOther. Anything described as frozen goes to the freezer aisle.
+func Aisle(item string) string {
+	name := " " + normalizeName(item) + " "
+	if strings.Contains(name, " frozen ") {
+		return AisleFrozen
+	}
+	for _, matcher := range aisleMatchers {
+		if strings.Contains(name, matcher.key) {
+			return matcher.aisle
+		}
+	}
+	return AisleOther
+}
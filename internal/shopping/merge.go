@@ -0,0 +1,242 @@
+// Package shopping merges the ingredients of several recipes into a single
+// shopping list, adding up like items and grouping them by store aisle.
+package shopping
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+
+	"recipe-ai/internal/units"
+)
+
+// Ingredient is an ingredient line going onto a list, with the factor its
+// quantity is scaled by for the servings being cooked.
+type Ingredient struct {
+	Quantity string
+	Unit     string
+	Item     string
+	Factor   float64
+}
+
+// Item is one line of a merged list. Quantity and Unit are empty for items
+// that no recipe gives an amount for, such as salt to taste.
+type Item struct {
+	Aisle    string
+	Item     string
+	Quantity string
+	Unit     string
+}
+
+// skippedItems never go on a list since nobody shops for them.
+var skippedItems = map[string]bool{
+	"water": true, "cold water": true, "warm water": true, "hot water": true,
+	"boiling water": true, "ice water": true, "ice": true,
+}
+
+// group collects the ingredients that merge into one list item.
+type group struct {
+	name   string
+	key    string
+	unit   string
+	amount units.Quantity
+	// counted is false for items without an amount; measured is true when
+	// amount is in ml or g, and allUS when every part was written in US units.
+	counted  bool
+	measured bool
+	allUS    bool
+}
+
+// Merge adds up like ingredients and returns the list in aisle order. Items
+// match by name, ignoring case and plurals. Volumes and weights are added in
+// millilitres or grams, so "2 cups milk" and "250 ml milk" become one item,
+// and shown in the target system. With SystemNone an item is shown in US units
+// if every recipe measured it that way, and in metric otherwise.
+func Merge(ingredients []Ingredient, target units.System) []Item {
+	var groups []*group
+	index := map[string]*group{}
+	find := func(key, unit string) *group {
+		g, ok := index[key+"|"+unit]
+		if !ok {
+			g = &group{key: key, unit: unit, allUS: true}
+			index[key+"|"+unit] = g
+			groups = append(groups, g)
+		}
+		return g
+	}
+
+	for _, ingredient := range ingredients {
+		name := strings.TrimSpace(ingredient.Item)
+		key := normalizeName(name)
+		if key == "" || skippedItems[key] {
+			continue
+		}
+
+		q, err := units.ParseQuantity(ingredient.Quantity)
+		if err != nil {
+			g := find(key, "")
+			if g.name == "" {
+				g.name = name
+			}
+			continue
+		}
+		if ingredient.Factor > 0 {
+			q = q.Scale(ingredient.Factor)
+		}
+
+		var g *group
+		if base, baseUnit, ok := units.ToMetricBase(q, ingredient.Unit, name); ok {
+			u, _ := units.Lookup(ingredient.Unit)
+			g = find(key, baseUnit)
+			g.measured = true
+			g.allUS = g.allUS && u.System == units.SystemUS
+			q = base
+		} else {
+			unit, ok := units.Canonical(ingredient.Unit)
+			if !ok {
+				unit = strings.ToLower(strings.TrimSpace(ingredient.Unit))
+			}
+			g = find(key, "#"+unit)
+			g.unit = unit
+		}
+		if g.name == "" {
+			g.name = name
+		}
+		g.counted = true
+		g.amount = units.Quantity{Min: g.amount.Min + q.Min, Max: g.amount.Max + q.Max}
+	}
+
+	// An item listed "to taste" in one recipe and measured in another only
+	// needs the measured line.
+	counted := map[string]bool{}
+	for _, g := range groups {
+		if g.counted {
+			counted[g.key] = true
+		}
+	}
+
+	items := make([]Item, 0, len(groups))
+	for _, g := range groups {
+		if !g.counted && counted[g.key] {
+			continue
+		}
+		items = append(items, g.item(target))
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		ai, aj := slices.Index(Aisles, items[i].Aisle), slices.Index(Aisles, items[j].Aisle)
+		if ai != aj {
+			return ai < aj
+		}
+		return strings.ToLower(items[i].Item) < strings.ToLower(items[j].Item)
+	})
+	return items
+}
+
+func (g *group) item(target units.System) Item {
+	item := Item{Aisle: Aisle(g.name), Item: g.name}
+	if !g.counted {
+		return item
+	}
+
+	amount, unit := g.amount, g.unit
+	if g.measured && (target == units.SystemUS || target == units.SystemNone && g.allUS) {
+		amount, unit, _ = units.Convert(amount, unit, g.name, units.SystemUS)
+	} else {
+		amount, unit = units.ScaleWithUnit(amount, unit, 1)
+	}
+
+	item.Quantity = amount.Format(unit)
+	item.Unit = unit
+	return item
+}
+
+// Line renders a list item as it is written on paper, such as "2 cups milk".
+func Line(quantity, unit, item string) string {
+	parts := make([]string, 0, 3)
+	if quantity != "" {
+		parts = append(parts, quantity)
+	}
+	if unit != "" {
+		parts = append(parts, units.DisplayUnit(unit, quantity))
+	}
+	return strings.Join(append(parts, item), " ")
+}
+
+// Have reports whether an item on hand provides an ingredient a recipe calls
+// for. The item must name the ingredient itself or its head noun, the words it
+// ends with, ignoring case and plurals: "flour" provides "all-purpose flour",
+// but "chicken" does not provide "chicken stock". Compound names such as "bell
+// pepper" or "ice cream" must be on hand in full. Water never needs buying.
+func Have(onHand []string, need string) bool {
+	key := normalizeName(need)
+	if key == "" || skippedItems[key] {
+		return true
+	}
+	head := headWords(key)
+	for _, item := range onHand {
+		have := normalizeName(item)
+		if have != "" && strings.HasSuffix(" "+key, " "+have) && len(strings.Fields(have)) >= head {
+			return true
+		}
+	}
+	return false
+}
+
+// compoundNames are ingredients whose last word alone names something else.
+var compoundNames = []string{
+	"ice cream", "sour cream", "cream cheese", "bell pepper", "chili pepper",
+	"peanut butter", "coconut milk", "sweet potato", "green onion",
+	"spring onion", "baking powder", "baking soda", "soy sauce", "fish sauce",
+	"hot sauce", "tomato paste", "tomato sauce",
+}
+
+// headWords returns how many trailing words of a normalized ingredient name
+// an item on hand must match: those of a compound name it ends with, or one.
+func headWords(key string) int {
+	for _, compound := range compoundNames {
+		if strings.HasSuffix(" "+key, " "+normalizeName(compound)) {
+			return len(strings.Fields(compound))
+		}
+	}
+	return 1
+}
+
+// Includes reports whether item is one of names, ignoring case and plurals.
+// Unlike Have, "black pepper" is not taken to be "pepper".
+func Includes(names []string, item string) bool {
+	key := normalizeName(item)
+	for _, name := range names {
+		if normalizeName(name) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeName lowercases an ingredient name, drops punctuation and
+// singularizes each word so that "Tomatoes" and "tomato" match.
+func normalizeName(name string) string {
+	words := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = singular(word)
+	}
+	return strings.Join(words, " ")
+}
+
+func singular(word string) string {
+	switch {
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "oes"), strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"), strings.HasSuffix(word, "xes"):
+		return strings.TrimSuffix(word, "es")
+	case len(word) > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") && !strings.HasSuffix(word, "us") && !strings.HasSuffix(word, "is"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
@@ -0,0 +1,163 @@
+package shopping
+
+import (
+	"reflect"
+	"testing"
+
+	"recipe-ai/internal/units"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name        string
+		ingredients []Ingredient
+		target      units.System
+		want        []Item
+	}{
+		{
+			name: "volumes in both systems add up in metric",
+			ingredients: []Ingredient{
+				{Quantity: "1", Unit: "cup", Item: "milk", Factor: 2},
+				{Quantity: "250", Unit: "ml", Item: "Milk"},
+			},
+			want: []Item{{Aisle: AisleDairy, Item: "milk", Quantity: "723", Unit: "ml"}},
+		},
+		{
+			name: "US-only amounts stay in US units",
+			ingredients: []Ingredient{
+				{Quantity: "2", Unit: "tbsp", Item: "butter"},
+				{Quantity: "2", Unit: "tablespoons", Item: "Butter"},
+			},
+			want: []Item{{Aisle: AisleDairy, Item: "butter", Quantity: "1/4", Unit: "cup"}},
+		},
+		{
+			name: "volume and weight of a dense ingredient convert to the target",
+			ingredients: []Ingredient{
+				{Quantity: "1", Unit: "cup", Item: "flour"},
+				{Quantity: "100", Unit: "g", Item: "flour"},
+			},
+			target: units.SystemUS,
+			want:   []Item{{Aisle: AislePantry, Item: "flour", Quantity: "1 3/4", Unit: "cup"}},
+		},
+		{
+			name: "counted items add up by unit and plural",
+			ingredients: []Ingredient{
+				{Quantity: "2", Unit: "cloves", Item: "garlic"},
+				{Quantity: "1", Unit: "clove", Item: "garlic"},
+				{Quantity: "2", Item: "eggs"},
+				{Quantity: "1", Item: "egg", Factor: 1.5},
+			},
+			want: []Item{
+				{Aisle: AisleProduce, Item: "garlic", Quantity: "3", Unit: "clove"},
+				{Aisle: AisleDairy, Item: "eggs", Quantity: "3 1/2"},
+			},
+		},
+		{
+			name: "to taste is dropped when another recipe measures the item",
+			ingredients: []Ingredient{
+				{Quantity: "to taste", Item: "salt"},
+				{Quantity: "1", Unit: "tsp", Item: "salt"},
+				{Item: "black pepper"},
+				{Item: "Black Pepper"},
+			},
+			want: []Item{
+				{Aisle: AisleSpices, Item: "black pepper"},
+				{Aisle: AisleSpices, Item: "salt", Quantity: "1", Unit: "tsp"},
+			},
+		},
+		{
+			name: "water is never listed",
+			ingredients: []Ingredient{
+				{Quantity: "2", Unit: "cups", Item: "water"},
+				{Quantity: "1", Unit: "cup", Item: "Cold water"},
+				{Item: ""},
+			},
+			want: []Item{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.ingredients, tt.target); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLine(t *testing.T) {
+	tests := []struct {
+		quantity, unit, item string
+		want                 string
+	}{
+		{"2", "cup", "milk", "2 cups milk"},
+		{"1", "clove", "garlic", "1 clove garlic"},
+		{"3", "", "eggs", "3 eggs"},
+		{"", "", "salt", "salt"},
+	}
+
+	for _, tt := range tests {
+		if got := Line(tt.quantity, tt.unit, tt.item); got != tt.want {
+			t.Errorf("Line(%q, %q, %q) = %q, want %q", tt.quantity, tt.unit, tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestHave(t *testing.T) {
+	tests := []struct {
+		onHand []string
+		need   string
+		want   bool
+	}{
+		{[]string{"flour"}, "all-purpose flour", true},
+		{[]string{"Tomatoes"}, "tomato", true},
+		{[]string{"tomato"}, "cherry tomatoes", true},
+		{[]string{"chicken breast"}, "boneless chicken breasts", true},
+		{[]string{"pepper"}, "black pepper", true},
+		{[]string{"rice", "onion"}, "red onion", true},
+		{nil, "water", true},
+		{nil, "cold water", true},
+
+		{[]string{"chicken"}, "chicken stock", false},
+		{[]string{"chicken"}, "chicken breast", false},
+		{[]string{"cream"}, "ice cream", false},
+		{[]string{"cream"}, "sour cream", false},
+		{[]string{"pepper"}, "bell pepper", false},
+		{[]string{"pepper"}, "red bell peppers", false},
+		{[]string{"butter"}, "peanut butter", false},
+		{[]string{"sauce"}, "soy sauce", false},
+		{[]string{"all-purpose flour"}, "flour", false},
+		{[]string{"ice cream"}, "cream", false},
+		{[]string{"bell pepper"}, "red bell pepper", true},
+		{[]string{"rice"}, "ricotta", false},
+		{nil, "salt", false},
+		{[]string{""}, "salt", false},
+	}
+
+	for _, tt := range tests {
+		if got := Have(tt.onHand, tt.need); got != tt.want {
+			t.Errorf("Have(%q, %q) = %v, want %v", tt.onHand, tt.need, got, tt.want)
+		}
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	staples := []string{"salt", "pepper", "olive oil"}
+	tests := []struct {
+		item string
+		want bool
+	}{
+		{"Salt", true},
+		{"olive oils", true},
+		{"pepper", true},
+		{"black pepper", false},
+		{"bell pepper", false},
+		{"oil", false},
+	}
+
+	for _, tt := range tests {
+		if got := Includes(staples, tt.item); got != tt.want {
+			t.Errorf("Includes(staples, %q) = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+}
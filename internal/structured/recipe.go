@@ -0,0 +1,245 @@
+package structured
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const ToolName = "record_recipe"
+
+type Recipe struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	PrepTime    string       `json:"prep_time"`
+	CookTime    string       `json:"cook_time"`
+	Servings    int          `json:"servings"`
+	Ingredients []Ingredient `json:"ingredients"`
+	Steps       []string     `json:"steps"`
+	Nutrition   Nutrition    `json:"nutrition"`
+	Tips        []string     `json:"tips"`
+	Tags        []string     `json:"tags,omitempty"`
+}
+
+type Ingredient struct {
+	Quantity string `json:"quantity"`
+	Unit     string `json:"unit"`
+	Item     string `json:"item"`
+	Note     string `json:"note"`
+}
+
+type Nutrition struct {
+	Calories      string `json:"calories"`
+	Protein       string `json:"protein"`
+	Carbohydrates string `json:"carbohydrates"`
+	Fat           string `json:"fat"`
+}
+
+// Schema is the JSON schema the model is forced to fill in. It mirrors Recipe
+// field for field so a strict decode catches anything the model invents.
+func Schema() map[string]interface{} {
+	str := func(description string) map[string]interface{} {
+		return map[string]interface{}{"type": "string", "description": description}
+	}
+	strList := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"type":        "array",
+			"description": description,
+			"items":       map[string]interface{}{"type": "string"},
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"name", "description", "prep_time", "cook_time", "servings", "ingredients", "steps", "nutrition", "tips"},
+		"properties": map[string]interface{}{
+			"name":        str("Recipe name"),
+			"description": str("One or two sentence description of the dish"),
+			"prep_time":   str("Total prep time, e.g. \"15 minutes\""),
+			"cook_time":   str("Total cooking time, e.g. \"30 minutes\""),
+			"servings": map[string]interface{}{
+				"type":        "integer",
+				"minimum":     1,
+				"description": "Number of people the recipe serves",
+			},
+			"ingredients": map[string]interface{}{
+				"type":     "array",
+				"minItems": 1,
+				"items": map[string]interface{}{
+					"type":                 "object",
+					"additionalProperties": false,
+					"required":             []string{"quantity", "unit", "item", "note"},
+					"properties": map[string]interface{}{
+						"quantity": str("Amount such as \"2\", \"1 1/2\" or \"2-3\"; empty if unmeasured"),
+						"unit":     str("Unit of measure such as \"cup\" or \"g\"; empty for countable items"),
+						"item":     str("Ingredient name"),
+						"note":     str("Preparation note such as \"finely chopped\"; may be empty"),
+					},
+				},
+			},
+			"steps": map[string]interface{}{
+				"type":        "array",
+				"minItems":    1,
+				"description": "Ordered cooking instructions, one step per entry without numbering",
+				"items":       map[string]interface{}{"type": "string"},
+			},
+			"nutrition": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": false,
+				"required":             []string{"calories", "protein", "carbohydrates", "fat"},
+				"properties": map[string]interface{}{
+					"calories":      str("Approximate calories per serving"),
+					"protein":       str("Approximate protein per serving"),
+					"carbohydrates": str("Approximate carbohydrates per serving"),
+					"fat":           str("Approximate fat per serving"),
+				},
+			},
+			"tips": strList("Tips or variations"),
+		},
+	}
+}
+
+// MaxTags is the most tags SchemaWithTags lets the model suggest.
+const MaxTags = 5
+
+// SchemaWithTags extends Schema with a list of suggested tags.
+func SchemaWithTags() map[string]interface{} {
+	schema := Schema()
+	schema["required"] = append(schema["required"].([]string), "tags")
+	schema["properties"].(map[string]interface{})["tags"] = map[string]interface{}{
+		"type":        "array",
+		"maxItems":    MaxTags,
+		"description": "Short lowercase tags describing the dish, such as \"weeknight\", \"freezer-friendly\" or \"kid-approved\"",
+		"items":       map[string]interface{}{"type": "string"},
+	}
+	return schema
+}
+
+func Parse(data []byte) (*Recipe, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var recipe Recipe
+	if err := decoder.Decode(&recipe); err != nil {
+		return nil, fmt.Errorf("invalid recipe JSON: %w", err)
+	}
+
+	recipe.normalize()
+	if err := recipe.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func (r *Recipe) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("recipe name is required")
+	}
+	if len(r.Name) > 200 {
+		return fmt.Errorf("recipe name is too long (maximum 200 characters)")
+	}
+	if r.Servings < 1 {
+		return fmt.Errorf("servings must be at least 1")
+	}
+	if len(r.Ingredients) == 0 {
+		return fmt.Errorf("recipe must have at least one ingredient")
+	}
+	for i, ingredient := range r.Ingredients {
+		if ingredient.Item == "" {
+			return fmt.Errorf("ingredient %d is missing an item name", i+1)
+		}
+	}
+	if len(r.Steps) == 0 {
+		return fmt.Errorf("recipe must have at least one step")
+	}
+	for i, step := range r.Steps {
+		if step == "" {
+			return fmt.Errorf("step %d is empty", i+1)
+		}
+	}
+	return nil
+}
+
+func (r *Recipe) normalize() {
+	r.Name = strings.TrimSpace(r.Name)
+	r.Description = strings.TrimSpace(r.Description)
+	r.PrepTime = strings.TrimSpace(r.PrepTime)
+	r.CookTime = strings.TrimSpace(r.CookTime)
+	for i := range r.Ingredients {
+		r.Ingredients[i].Quantity = strings.TrimSpace(r.Ingredients[i].Quantity)
+		r.Ingredients[i].Unit = strings.TrimSpace(r.Ingredients[i].Unit)
+		r.Ingredients[i].Item = strings.TrimSpace(r.Ingredients[i].Item)
+		r.Ingredients[i].Note = strings.TrimSpace(r.Ingredients[i].Note)
+	}
+	for i := range r.Steps {
+		r.Steps[i] = strings.TrimSpace(r.Steps[i])
+	}
+	for i := range r.Tags {
+		r.Tags[i] = strings.TrimSpace(r.Tags[i])
+	}
+}
+
+func (i Ingredient) String() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{i.Quantity, i.Unit, i.Item} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	line := strings.Join(parts, " ")
+	if i.Note != "" {
+		line += ", " + i.Note
+	}
+	return line
+}
+
+// Render produces the plain-text form stored in recipe_content, laid out the
+// same way the free-text prompt asks the model to format its answer.
+func (r *Recipe) Render() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "Recipe Name: %s\n\n", r.Name)
+	if r.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", r.Description)
+	}
+
+	fmt.Fprintf(&b, "Prep Time: %s\n", getStringValue(r.PrepTime, "N/A"))
+	fmt.Fprintf(&b, "Cook Time: %s\n", getStringValue(r.CookTime, "N/A"))
+	fmt.Fprintf(&b, "Servings: %d\n\n", r.Servings)
+
+	b.WriteString("Ingredients:\n")
+	for _, ingredient := range r.Ingredients {
+		fmt.Fprintf(&b, "- %s\n", ingredient)
+	}
+
+	b.WriteString("\nStep-by-Step Instructions:\n")
+	for i, step := range r.Steps {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, step)
+	}
+
+	b.WriteString("\nNutritional Information (approximate, per serving):\n")
+	fmt.Fprintf(&b, "- Calories: %s\n", getStringValue(r.Nutrition.Calories, "N/A"))
+	fmt.Fprintf(&b, "- Protein: %s\n", getStringValue(r.Nutrition.Protein, "N/A"))
+	fmt.Fprintf(&b, "- Carbohydrates: %s\n", getStringValue(r.Nutrition.Carbohydrates, "N/A"))
+	fmt.Fprintf(&b, "- Fat: %s\n", getStringValue(r.Nutrition.Fat, "N/A"))
+
+	if len(r.Tips) > 0 {
+		b.WriteString("\nTips:\n")
+		for _, tip := range r.Tips {
+			fmt.Fprintf(&b, "- %s\n", tip)
+		}
+	}
+
+	return b.String()
+}
+
+func getStringValue(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,200 @@
+// Package textdiff computes line-level differences between two texts.
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Op string
+
+const (
+	OpEqual  Op = "equal"
+	OpInsert Op = "insert"
+	OpDelete Op = "delete"
+)
+
+// Line is one line of a diff. OldLine and NewLine are 1-based line numbers in
+// the old and new text, and zero when the line does not appear there.
+type Line struct {
+	Op      Op     `json:"op"`
+	Text    string `json:"text"`
+	OldLine int    `json:"old_line,omitempty"`
+	NewLine int    `json:"new_line,omitempty"`
+}
+
+// maxCells bounds the longest-common-subsequence table. Larger inputs fall
+// back to replacing every line outside the common prefix and suffix.
+const maxCells = 4_000_000
+
+// Lines diffs old and new line by line, returning every line of both texts in
+// order with deletions before insertions wherever lines were replaced.
+func Lines(old, new string) []Line {
+	a, b := splitLines(old), splitLines(new)
+
+	// Trim the common prefix and suffix, which is most of a typical edit.
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: OpEqual, Text: a[i], OldLine: i + 1, NewLine: i + 1})
+	}
+
+	lines = append(lines, middle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix)...)
+
+	for i := 0; i < suffix; i++ {
+		oldIndex, newIndex := len(a)-suffix+i, len(b)-suffix+i
+		lines = append(lines, Line{Op: OpEqual, Text: a[oldIndex], OldLine: oldIndex + 1, NewLine: newIndex + 1})
+	}
+
+	return lines
+}
+
+// middle diffs the part of both texts between their common prefix and suffix
+// using a longest common subsequence table. offset is the prefix length.
+func middle(a, b []string, offset int) []Line {
+	var lines []Line
+	if len(a)*len(b) > maxCells {
+		for i, text := range a {
+			lines = append(lines, Line{Op: OpDelete, Text: text, OldLine: offset + i + 1})
+		}
+		for j, text := range b {
+			lines = append(lines, Line{Op: OpInsert, Text: text, NewLine: offset + j + 1})
+		}
+		return lines
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, Line{Op: OpEqual, Text: a[i], OldLine: offset + i + 1, NewLine: offset + j + 1})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, Line{Op: OpDelete, Text: a[i], OldLine: offset + i + 1})
+			i++
+		default:
+			lines = append(lines, Line{Op: OpInsert, Text: b[j], NewLine: offset + j + 1})
+			j++
+		}
+	}
+	return lines
+}
+
+// Stats counts inserted and deleted lines.
+func Stats(lines []Line) (inserted, deleted int) {
+	for _, line := range lines {
+		switch line.Op {
+		case OpInsert:
+			inserted++
+		case OpDelete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// Unified renders a diff in unified format with the given number of context
+// lines around each change.
+func Unified(lines []Line, oldName, newName string, context int) string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+
+	for start := 0; start < len(lines); {
+		// Find the next change and the extent of its hunk, merging changes
+		// whose context would overlap.
+		first := start
+		for first < len(lines) && lines[first].Op == OpEqual {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+
+		end := first
+		for end < len(lines) {
+			next := end
+			for next < len(lines) && lines[next].Op != OpEqual {
+				next++
+			}
+			gap := next
+			for gap < len(lines) && lines[gap].Op == OpEqual {
+				gap++
+			}
+			end = next
+			if gap == len(lines) || gap-next > 2*context {
+				break
+			}
+			end = gap
+		}
+
+		from := max(first-context, start)
+		to := min(end+context, len(lines))
+		writeHunk(&out, lines[from:to])
+		start = to
+	}
+
+	return out.String()
+}
+
+func writeHunk(out *strings.Builder, hunk []Line) {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, line := range hunk {
+		if line.OldLine > 0 {
+			if oldStart == 0 {
+				oldStart = line.OldLine
+			}
+			oldCount++
+		}
+		if line.NewLine > 0 {
+			if newStart == 0 {
+				newStart = line.NewLine
+			}
+			newCount++
+		}
+	}
+	fmt.Fprintf(out, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+
+	for _, line := range hunk {
+		switch line.Op {
+		case OpEqual:
+			out.WriteString(" ")
+		case OpDelete:
+			out.WriteString("-")
+		case OpInsert:
+			out.WriteString("+")
+		}
+		out.WriteString(line.Text)
+		out.WriteString("\n")
+	}
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
@@ -0,0 +1,194 @@
+package textdiff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		old, new string
+		want     []Line
+	}{
+		{
+			name: "identical",
+			old:  "a\nb\n",
+			new:  "a\nb",
+			want: []Line{
+				{Op: OpEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: OpEqual, Text: "b", OldLine: 2, NewLine: 2},
+			},
+		},
+		{
+			name: "replaced line",
+			old:  "a\nb\nc",
+			new:  "a\nB\nc",
+			want: []Line{
+				{Op: OpEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: OpDelete, Text: "b", OldLine: 2},
+				{Op: OpInsert, Text: "B", NewLine: 2},
+				{Op: OpEqual, Text: "c", OldLine: 3, NewLine: 3},
+			},
+		},
+		{
+			name: "insert and delete",
+			old:  "a\nb\nc\nd",
+			new:  "a\nc\nd\ne",
+			want: []Line{
+				{Op: OpEqual, Text: "a", OldLine: 1, NewLine: 1},
+				{Op: OpDelete, Text: "b", OldLine: 2},
+				{Op: OpEqual, Text: "c", OldLine: 3, NewLine: 2},
+				{Op: OpEqual, Text: "d", OldLine: 4, NewLine: 3},
+				{Op: OpInsert, Text: "e", NewLine: 4},
+			},
+		},
+		{
+			name: "common line inside a change",
+			old:  "x\nsame\ny",
+			new:  "p\nsame\nq",
+			want: []Line{
+				{Op: OpDelete, Text: "x", OldLine: 1},
+				{Op: OpInsert, Text: "p", NewLine: 1},
+				{Op: OpEqual, Text: "same", OldLine: 2, NewLine: 2},
+				{Op: OpDelete, Text: "y", OldLine: 3},
+				{Op: OpInsert, Text: "q", NewLine: 3},
+			},
+		},
+		{
+			name: "from empty",
+			old:  "",
+			new:  "a\r\nb\r\n",
+			want: []Line{
+				{Op: OpInsert, Text: "a", NewLine: 1},
+				{Op: OpInsert, Text: "b", NewLine: 2},
+			},
+		},
+		{
+			name: "to empty",
+			old:  "a",
+			new:  "",
+			want: []Line{{Op: OpDelete, Text: "a", OldLine: 1}},
+		},
+		{
+			name: "both empty",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStats(t *testing.T) {
+	inserted, deleted := Stats(Lines("a\nb\nc", "a\nB\nc\nd"))
+	if inserted != 2 || deleted != 1 {
+		t.Errorf("Stats() = %d, %d, want 2, 1", inserted, deleted)
+	}
+}
+
+func TestUnified(t *testing.T) {
+	numbered := func(n int) string {
+		lines := make([]string, n)
+		for i := range lines {
+			lines[i] = string(rune('a' + i))
+		}
+		return strings.Join(lines, "\n")
+	}
+	long := numbered(12)
+
+	tests := []struct {
+		name     string
+		old, new string
+		context  int
+		want     string
+	}{
+		{
+			name:    "no changes",
+			old:     "a\nb",
+			new:     "a\nb",
+			context: 3,
+			want:    "--- old\n+++ new\n",
+		},
+		{
+			name:    "single change with context",
+			old:     long,
+			new:     strings.Replace(long, "f", "F", 1),
+			context: 2,
+			want: `--- old
++++ new
+@@ -4,5 +4,5 @@
+ d
+ e
+-f
++F
+ g
+ h
+`,
+		},
+		{
+			name:    "distant changes make separate hunks",
+			old:     long,
+			new:     strings.Replace(strings.Replace(long, "b", "B", 1), "k", "K", 1),
+			context: 1,
+			want: `--- old
++++ new
+@@ -1,3 +1,3 @@
+ a
+-b
++B
+ c
+@@ -10,3 +10,3 @@
+ j
+-k
++K
+ l
+`,
+		},
+		{
+			name:    "nearby changes share a hunk",
+			old:     long,
+			new:     strings.Replace(strings.Replace(long, "c", "C", 1), "f", "F", 1),
+			context: 1,
+			want: `--- old
++++ new
+@@ -2,6 +2,6 @@
+ b
+-c
++C
+ d
+ e
+-f
++F
+ g
+`,
+		},
+		{
+			name:    "insertion at the end",
+			old:     "a\nb",
+			new:     "a\nb\nc",
+			context: 3,
+			want: `--- old
++++ new
+@@ -1,2 +1,3 @@
+ a
+ b
++c
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Unified(Lines(tt.old, tt.new), "old", "new", tt.context)
+			if got != tt.want {
+				t.Errorf("Unified() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,311 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	millilitersPerTeaspoon = 4.92892159375
+	millilitersPerCup      = 48 * millilitersPerTeaspoon
+	gramsPerOunce          = 28.349523125
+)
+
+var (
+	temperaturePattern = regexp.MustCompile(`(\d{2,3})\s*(?:°|º|degrees?\s*)\s*([FfCc])\b`)
+	lineMarkerPattern  = regexp.MustCompile(`^(\s*(?:[-*•‣▪]\s*)?)`)
+)
+
+// densities holds grams per US cup for ingredients that are commonly measured
+// by volume in US recipes and by weight in metric ones. Keys are matched
+// against the whole words an ingredient name ends with, longest first, so
+// "brown sugar" wins over "sugar" while "rice vinegar" and "buttermilk" match
+// nothing. Liquids such as milk and oil are deliberately absent so they
+// convert to millilitres instead.
+var densities = map[string]float64{
+	"flour":                125,
+	"all-purpose flour":    125,
+	"bread flour":          127,
+	"whole wheat flour":    120,
+	"cake flour":           114,
+	"almond flour":         96,
+	"sugar":                200,
+	"granulated sugar":     200,
+	"brown sugar":          213,
+	"powdered sugar":       120,
+	"confectioners' sugar": 120,
+	"butter":               227,
+	"cocoa":                85,
+	"cocoa powder":         85,
+	"cornstarch":           128,
+	"oats":                 90,
+	"rolled oats":          90,
+	"rice":                 185,
+	"honey":                340,
+	"yogurt":               245,
+	"salt":                 288,
+	"kosher salt":          135,
+	"baking soda":          220,
+	"baking powder":        192,
+	"chocolate chips":      170,
+	"grated parmesan":      100,
+	"shredded cheese":      113,
+	"breadcrumbs":          108,
+	"nuts":                 120,
+	"raisins":              145,
+}
+
+var densityKeys = func() []string {
+	keys := make([]string, 0, len(densities))
+	for key := range densities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}()
+
+// densityNotePattern finds where the ingredient ends and a note such as
+// ", softened" or "for dusting" begins.
+var densityNotePattern = regexp.MustCompile(`[,;(]|\s(?:for|to|or|at)\s`)
+
+func ParseSystem(s string) (System, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "":
+		return SystemNone, nil
+	case "metric":
+		return SystemMetric, nil
+	case "imperial", "us":
+		return SystemUS, nil
+	default:
+		return SystemNone, fmt.Errorf("unknown unit system %q (use metric or imperial)", s)
+	}
+}
+
+// Density returns grams per US cup for an ingredient name, if known.
+func Density(item string) (float64, bool) {
+	item = strings.ToLower(item)
+	if loc := densityNotePattern.FindStringIndex(item); loc != nil {
+		item = item[:loc[0]]
+	}
+	words := densityWords(item)
+	for _, key := range densityKeys {
+		if endsWithWords(words, densityWords(key)) {
+			return densities[key], true
+		}
+	}
+	return 0, false
+}
+
+func densityWords(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+	})
+}
+
+// endsWithWords reports whether words ends with suffix, allowing the last
+// word to differ by a plural "s".
+func endsWithWords(words, suffix []string) bool {
+	if len(suffix) == 0 || len(words) < len(suffix) {
+		return false
+	}
+	words = words[len(words)-len(suffix):]
+	last := len(suffix) - 1
+	if !slices.Equal(words[:last], suffix[:last]) {
+		return false
+	}
+	a, b := words[last], suffix[last]
+	return a == b || a == b+"s" || a+"s" == b
+}
+
+// Convert expresses a quantity in the target system. US volumes of ingredients
+// with a known density become grams in metric, and metric weights of those
+// ingredients become cups in imperial, matching how each system's cooks
+// measure. It reports false when nothing needed converting.
+func Convert(q Quantity, unit, item string, target System) (Quantity, string, bool) {
+	u, ok := Lookup(unit)
+	if !ok || target == SystemNone || u.System == target {
+		return q, unit, false
+	}
+
+	density, hasDensity := Density(item)
+
+	var factor float64
+	var targetUnit string
+	switch {
+	case target == SystemMetric && u.Dimension == DimensionVolume && hasDensity:
+		factor = u.Base * millilitersPerTeaspoon / millilitersPerCup * density
+		targetUnit = "g"
+	case target == SystemMetric && u.Dimension == DimensionVolume:
+		factor = u.Base * millilitersPerTeaspoon
+		targetUnit = "ml"
+	case target == SystemMetric && u.Dimension == DimensionWeight:
+		factor = u.Base * gramsPerOunce
+		targetUnit = "g"
+	case target == SystemUS && u.Dimension == DimensionWeight && hasDensity:
+		factor = u.Base / density * knownUnits["cup"].Base
+		targetUnit = "tsp"
+	case target == SystemUS && u.Dimension == DimensionWeight:
+		factor = u.Base / gramsPerOunce
+		targetUnit = "oz"
+	case target == SystemUS && u.Dimension == DimensionVolume:
+		factor = u.Base / millilitersPerTeaspoon
+		targetUnit = "tsp"
+	default:
+		return q, unit, false
+	}
+
+	converted, convertedUnit := promoteQuantity(q.Scale(factor), targetUnit)
+	return converted, convertedUnit, true
+}
+
+// ToMetricBase expresses a measured quantity in millilitres or grams so that
+// amounts written in different units can be added up. Volumes of ingredients
+// with a known density become grams, so "1 cup flour" and "100 g flour" can be
+// combined. It reports false for units without a dimension, such as cloves.
+func ToMetricBase(q Quantity, unit, item string) (Quantity, string, bool) {
+	u, ok := Lookup(unit)
+	if !ok {
+		return q, unit, false
+	}
+
+	// Metric base units are 1 ml and 1 g; US ones are 1 tsp and 1 oz.
+	milliliters := u.Base
+	grams := u.Base
+	if u.System == SystemUS {
+		milliliters *= millilitersPerTeaspoon
+		grams *= gramsPerOunce
+	}
+
+	if u.Dimension == DimensionWeight {
+		return q.Scale(grams), "g", true
+	}
+	if density, ok := Density(item); ok {
+		return q.Scale(milliliters / millilitersPerCup * density), "g", true
+	}
+	return q.Scale(milliliters), "ml", true
+}
+
+// ConvertText converts a quantity and unit as written on an ingredient line,
+// pluralizing the unit to suit the new quantity.
+func ConvertText(quantity, unit, item string, target System) (string, string, bool) {
+	q, err := ParseQuantity(quantity)
+	if err != nil {
+		return quantity, unit, false
+	}
+
+	converted, convertedUnit, ok := Convert(q, unit, item, target)
+	if !ok {
+		return quantity, unit, false
+	}
+	formatted := converted.Format(convertedUnit)
+	return formatted, DisplayUnit(convertedUnit, formatted), true
+}
+
+// ConvertLine converts the measurement at the start of a single ingredient
+// line such as "- 2 cups flour, sifted", preserving any list marker.
+func ConvertLine(line string, target System) string {
+	prefix := lineMarkerPattern.FindString(line)
+	rest := line[len(prefix):]
+
+	quantity, afterQuantity := SplitQuantity(rest)
+	if quantity == "" {
+		return ConvertTemperatures(line, target)
+	}
+	unit, item, ok := SplitUnit(afterQuantity)
+	if !ok {
+		return ConvertTemperatures(line, target)
+	}
+
+	convertedQuantity, convertedUnit, ok := ConvertText(quantity, unit, item, target)
+	if !ok {
+		return line
+	}
+	return prefix + convertedQuantity + " " + DisplayUnit(convertedUnit, convertedQuantity) + " " + item
+}
+
+var pluralUnits = map[string]string{
+	"cup":     "cups",
+	"pint":    "pints",
+	"quart":   "quarts",
+	"gallon":  "gallons",
+	"pinch":   "pinches",
+	"dash":    "dashes",
+	"clove":   "cloves",
+	"can":     "cans",
+	"slice":   "slices",
+	"stick":   "sticks",
+	"package": "packages",
+	"bunch":   "bunches",
+	"sprig":   "sprigs",
+	"piece":   "pieces",
+	"handful": "handfuls",
+}
+
+// DisplayUnit pluralizes a unit for display after quantity, so "2 cup" reads
+// as "2 cups" and "1 cloves" as "1 clove". Abbreviated units are left as they
+// are.
+func DisplayUnit(unit, quantity string) string {
+	canonical, ok := Canonical(unit)
+	plural, hasPlural := pluralUnits[canonical]
+	if !ok || !hasPlural {
+		return unit
+	}
+	if q, err := ParseQuantity(quantity); err == nil && q.Max > 1 {
+		return plural
+	}
+	return canonical
+}
+
+// ConvertContent converts every ingredient line and oven temperature in free
+// recipe text.
+func ConvertContent(content string, target System) string {
+	if target == SystemNone {
+		return content
+	}
+
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = ConvertLine(line, target)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ConvertTemperatures rewrites temperatures such as "350°F" or "180 degrees C"
+// into the target system, rounded to the nearest 5 degrees.
+func ConvertTemperatures(text string, target System) string {
+	if target == SystemNone {
+		return text
+	}
+
+	return temperaturePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := temperaturePattern.FindStringSubmatch(match)
+		degrees, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return match
+		}
+
+		scale := strings.ToUpper(parts[2])
+		switch {
+		case scale == "F" && target == SystemMetric:
+			return fmt.Sprintf("%d°C", roundToFive((degrees-32)*5/9))
+		case scale == "C" && target == SystemUS:
+			return fmt.Sprintf("%d°F", roundToFive(degrees*9/5+32))
+		}
+		return match
+	})
+}
+
+func roundToFive(value float64) int {
+	return int(math.Round(value/5) * 5)
+}
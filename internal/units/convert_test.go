@@ -0,0 +1,121 @@
+package units
+
+import "testing"
+
+func TestConvertText(t *testing.T) {
+	tests := []struct {
+		quantity, unit, item string
+		target               System
+		wantQuantity         string
+		wantUnit             string
+		wantOK               bool
+	}{
+		{"1", "cup", "flour", SystemMetric, "125", "g", true},
+		{"2", "cups", "all-purpose flour", SystemMetric, "250", "g", true},
+		{"1", "cup", "milk", SystemMetric, "237", "ml", true},
+		{"1", "cup", "buttermilk", SystemMetric, "237", "ml", true},
+		{"2", "tbsp", "rice vinegar", SystemMetric, "29.6", "ml", true},
+		{"1", "cup", "rice", SystemMetric, "185", "g", true},
+		{"1", "tbsp", "olive oil", SystemMetric, "14.8", "ml", true},
+		{"1", "lb", "chicken", SystemMetric, "454", "g", true},
+		{"5", "cups", "stock", SystemMetric, "1.18", "l", true},
+		{"1-2", "cups", "water", SystemMetric, "237-473", "ml", true},
+		{"250", "ml", "milk", SystemUS, "1", "cup", true},
+		{"125", "g", "flour", SystemUS, "1", "cup", true},
+		{"100", "g", "chicken", SystemUS, "3 1/2", "oz", true},
+		{"1", "kg", "potatoes", SystemUS, "2 1/4", "lb", true},
+		{"5", "ml", "vanilla", SystemUS, "1", "tsp", true},
+		{"1", "cup", "milk", SystemUS, "1", "cup", false},
+		{"2", "cloves", "garlic", SystemMetric, "2", "cloves", false},
+		{"to taste", "", "salt", SystemMetric, "to taste", "", false},
+		{"1", "cup", "milk", SystemNone, "1", "cup", false},
+	}
+
+	for _, tt := range tests {
+		quantity, unit, ok := ConvertText(tt.quantity, tt.unit, tt.item, tt.target)
+		if quantity != tt.wantQuantity || unit != tt.wantUnit || ok != tt.wantOK {
+			t.Errorf("ConvertText(%q, %q, %q, %v) = %q, %q, %v, want %q, %q, %v",
+				tt.quantity, tt.unit, tt.item, tt.target, quantity, unit, ok, tt.wantQuantity, tt.wantUnit, tt.wantOK)
+		}
+	}
+}
+
+func TestDensity(t *testing.T) {
+	tests := []struct {
+		item   string
+		want   float64
+		wantOK bool
+	}{
+		{"flour", 125, true},
+		{"All-Purpose Flour, sifted", 125, true},
+		{"flour for dusting", 125, true},
+		{"packed brown sugar", 213, true},
+		{"confectioners' sugar", 120, true},
+		{"unsalted butter (softened)", 227, true},
+		{"old-fashioned rolled oats", 90, true},
+		{"salted nuts", 120, true},
+		{"semi-sweet chocolate chip", 170, true},
+		{"unsweetened cocoa powder", 85, true},
+		{"buttermilk", 0, false},
+		{"rice vinegar", 0, false},
+		{"rice milk", 0, false},
+		{"sugar snap peas", 0, false},
+		{"goats cheese", 0, false},
+		{"peanut butter cups", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Density(tt.item)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Density(%q) = %v, %v, want %v, %v", tt.item, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestConvertLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		target System
+		want   string
+	}{
+		{"- 2 cups flour, sifted", SystemMetric, "- 250 g flour, sifted"},
+		{"* 1 cup milk", SystemMetric, "* 237 ml milk"},
+		{"1 tablespoon butter", SystemMetric, "14.2 g butter"},
+		{"- 500 g flour", SystemUS, "- 4 cups flour"},
+		{"- 3 cloves garlic", SystemMetric, "- 3 cloves garlic"},
+		{"- Salt to taste", SystemMetric, "- Salt to taste"},
+		{"Bake at 350°F for 20 minutes", SystemMetric, "Bake at 175°C for 20 minutes"},
+		{"Preheat the oven to 200 degrees C.", SystemUS, "Preheat the oven to 390°F."},
+		{"- 2 cups flour", SystemNone, "- 2 cups flour"},
+	}
+
+	for _, tt := range tests {
+		if got := ConvertLine(tt.line, tt.target); got != tt.want {
+			t.Errorf("ConvertLine(%q, %v) = %q, want %q", tt.line, tt.target, got, tt.want)
+		}
+	}
+}
+
+func TestDisplayUnit(t *testing.T) {
+	tests := []struct {
+		unit, quantity, want string
+	}{
+		{"cup", "2", "cups"},
+		{"cups", "1", "cup"},
+		{"cup", "1/2", "cup"},
+		{"cup", "1-2", "cups"},
+		{"cloves", "1", "clove"},
+		{"clove", "1 1/2", "cloves"},
+		{"pinch", "2", "pinches"},
+		{"Tablespoons", "2", "Tablespoons"},
+		{"tbsp", "2", "tbsp"},
+		{"g", "500", "g"},
+		{"", "2", ""},
+	}
+
+	for _, tt := range tests {
+		if got := DisplayUnit(tt.unit, tt.quantity); got != tt.want {
+			t.Errorf("DisplayUnit(%q, %q) = %q, want %q", tt.unit, tt.quantity, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package units
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const numberPattern = `(?:\d+\s+\d+/\d+|\d+\s*[½⅓⅔¼¾⅛⅜⅝⅞]|\d+/\d+|\d+(?:\.\d+)?|[½⅓⅔¼¾⅛⅜⅝⅞])`
+
+var (
+	leadingQuantityPattern = regexp.MustCompile(`^(` + numberPattern + `(?:\s*(?:-|–|to)\s*` + numberPattern + `)?)\s*`)
+	rangeSeparatorPattern  = regexp.MustCompile(`\s*(?:-|–|\bto\b)\s*`)
+)
+
+var unicodeFractions = map[rune]float64{
+	'½': 1.0 / 2, '⅓': 1.0 / 3, '⅔': 2.0 / 3, '¼': 1.0 / 4, '¾': 3.0 / 4,
+	'⅛': 1.0 / 8, '⅜': 3.0 / 8, '⅝': 5.0 / 8, '⅞': 7.0 / 8,
+}
+
+// Quantity is a parsed ingredient amount. Single amounts have Min == Max.
+type Quantity struct {
+	Min float64
+	Max float64
+}
+
+// SplitQuantity separates a leading amount such as "1 1/2" or "2-3" from the
+// rest of an ingredient line.
+func SplitQuantity(s string) (string, string) {
+	match := leadingQuantityPattern.FindStringSubmatch(s)
+	if match == nil {
+		return "", s
+	}
+	return strings.Join(strings.Fields(match[1]), " "), s[len(match[0]):]
+}
+
+func ParseQuantity(s string) (Quantity, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Quantity{}, fmt.Errorf("empty quantity")
+	}
+
+	parts := rangeSeparatorPattern.Split(s, 2)
+	min, err := parseNumber(parts[0])
+	if err != nil {
+		return Quantity{}, err
+	}
+	if len(parts) == 1 {
+		return Quantity{Min: min, Max: min}, nil
+	}
+
+	max, err := parseNumber(parts[1])
+	if err != nil {
+		return Quantity{}, err
+	}
+	if max < min {
+		return Quantity{}, fmt.Errorf("invalid range %q", s)
+	}
+	return Quantity{Min: min, Max: max}, nil
+}
+
+// IsRange reports whether the quantity spans two amounts, ignoring the
+// rounding error left by unit conversions.
+func (q Quantity) IsRange() bool {
+	return math.Abs(q.Max-q.Min) > 1e-9*math.Max(1, q.Max)
+}
+
+func (q Quantity) Scale(factor float64) Quantity {
+	return Quantity{Min: q.Min * factor, Max: q.Max * factor}
+}
+
+// Format renders the quantity for the given unit: fractions for US customary
+// and unitless amounts, decimals for metric.
+func (q Quantity) Format(unit string) string {
+	if !q.IsRange() {
+		return FormatAmount(q.Min, unit)
+	}
+	return FormatAmount(q.Min, unit) + "-" + FormatAmount(q.Max, unit)
+}
+
+// ScaleWithUnit scales the quantity and then promotes the unit so that, for
+// example, 8 tbsp doubled reads as 1 cup rather than 16 tbsp.
+func ScaleWithUnit(q Quantity, unit string, factor float64) (Quantity, string) {
+	return promoteQuantity(q.Scale(factor), unit)
+}
+
+// promoteQuantity promotes both ends of a range together, choosing the unit
+// from the larger end so "12-16 tbsp" becomes "3/4-1 cup".
+func promoteQuantity(q Quantity, unit string) (Quantity, string) {
+	if _, ok := Lookup(unit); !ok || q.Max == 0 {
+		return q, unit
+	}
+
+	max, promoted := Promote(q.Max, unit)
+	ratio := max / q.Max
+	return Quantity{Min: q.Min * ratio, Max: max}, promoted
+}
+
+func FormatAmount(amount float64, unit string) string {
+	if u, ok := Lookup(unit); ok && u.System == SystemMetric {
+		return formatDecimal(amount)
+	}
+	return formatFraction(amount)
+}
+
+func formatDecimal(amount float64) string {
+	switch {
+	case amount > 0 && amount < 0.01:
+		// Never show a positive amount as nothing at all.
+		return "0.01"
+	case amount >= 100:
+		return strconv.FormatFloat(math.Round(amount), 'f', -1, 64)
+	case amount >= 10:
+		return strconv.FormatFloat(math.Round(amount*10)/10, 'f', -1, 64)
+	default:
+		return strconv.FormatFloat(math.Round(amount*100)/100, 'f', -1, 64)
+	}
+}
+
+var kitchenFractions = []struct {
+	value float64
+	text  string
+}{
+	{0, ""}, {1.0 / 8, "1/8"}, {1.0 / 4, "1/4"}, {1.0 / 3, "1/3"}, {3.0 / 8, "3/8"},
+	{1.0 / 2, "1/2"}, {5.0 / 8, "5/8"}, {2.0 / 3, "2/3"}, {3.0 / 4, "3/4"}, {7.0 / 8, "7/8"}, {1, ""},
+}
+
+func formatFraction(amount float64) string {
+	whole := math.Floor(amount)
+	remainder := amount - whole
+
+	best := kitchenFractions[0]
+	for _, candidate := range kitchenFractions[1:] {
+		if math.Abs(candidate.value-remainder) < math.Abs(best.value-remainder) {
+			best = candidate
+		}
+	}
+	if best.value == 1 {
+		whole++
+	}
+	// Never show a positive amount as nothing at all.
+	if whole == 0 && best.value == 0 && amount > 0 {
+		best = kitchenFractions[1]
+	}
+
+	switch {
+	case best.text == "":
+		return strconv.FormatFloat(whole, 'f', 0, 64)
+	case whole == 0:
+		return best.text
+	default:
+		return strconv.FormatFloat(whole, 'f', 0, 64) + " " + best.text
+	}
+}
+
+func parseNumber(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	runes := []rune(s)
+	if fraction, ok := unicodeFractions[runes[len(runes)-1]]; ok {
+		whole := strings.TrimSpace(string(runes[:len(runes)-1]))
+		if whole == "" {
+			return fraction, nil
+		}
+		n, err := strconv.ParseFloat(whole, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		return n + fraction, nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) == 2 {
+		whole, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid number %q", s)
+		}
+		fraction, err := parseFraction(fields[1])
+		if err != nil {
+			return 0, err
+		}
+		return whole + fraction, nil
+	}
+	if len(fields) != 1 {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+
+	if strings.Contains(s, "/") {
+		return parseFraction(s)
+	}
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return n, nil
+}
+
+func parseFraction(s string) (float64, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid fraction %q", s)
+	}
+	numerator, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid fraction %q", s)
+	}
+	denominator, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || denominator == 0 {
+		return 0, fmt.Errorf("invalid fraction %q", s)
+	}
+	return numerator / denominator, nil
+}
+
+// ScaleText scales a quantity and unit as written on an ingredient line,
+// pluralizing the unit to suit the new quantity. It reports false, leaving
+// the input untouched, when the quantity cannot be parsed (for example "to
+// taste").
+func ScaleText(quantity, unit string, factor float64) (string, string, bool) {
+	q, err := ParseQuantity(quantity)
+	if err != nil {
+		return quantity, unit, false
+	}
+
+	scaled, scaledUnit := ScaleWithUnit(q, unit, factor)
+	formatted := scaled.Format(scaledUnit)
+	return formatted, DisplayUnit(scaledUnit, formatted), true
+}
+
+// ScaleLine scales the amount at the start of a single ingredient line such
+// as "- 2 cups flour, sifted", preserving any list marker. Lines without a
+// leading amount, such as "Salt to taste", are returned unchanged.
+func ScaleLine(line string, factor float64) string {
+	prefix := lineMarkerPattern.FindString(line)
+	rest := line[len(prefix):]
+
+	quantity, afterQuantity := SplitQuantity(rest)
+	if quantity == "" {
+		return line
+	}
+	unit, item, ok := SplitUnit(afterQuantity)
+	if !ok {
+		unit, item = "", afterQuantity
+	}
+
+	scaledQuantity, scaledUnit, ok := ScaleText(quantity, unit, factor)
+	if !ok {
+		return line
+	}
+	if scaledUnit == "" {
+		return prefix + scaledQuantity + " " + item
+	}
+	return prefix + scaledQuantity + " " + scaledUnit + " " + item
+}
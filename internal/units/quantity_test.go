@@ -0,0 +1,163 @@
+package units
+
+import "testing"
+
+func TestParseQuantity(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Quantity
+	}{
+		{"2", Quantity{2, 2}},
+		{"1.5", Quantity{1.5, 1.5}},
+		{"1/2", Quantity{0.5, 0.5}},
+		{"1 1/2", Quantity{1.5, 1.5}},
+		{"½", Quantity{0.5, 0.5}},
+		{"1½", Quantity{1.5, 1.5}},
+		{"1 ½", Quantity{1.5, 1.5}},
+		{"2-3", Quantity{2, 3}},
+		{"2 - 3", Quantity{2, 3}},
+		{"2–3", Quantity{2, 3}},
+		{"2 to 3", Quantity{2, 3}},
+		{"1/2-3/4", Quantity{0.5, 0.75}},
+		{" 4 ", Quantity{4, 4}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseQuantity(tt.in)
+		if err != nil {
+			t.Errorf("ParseQuantity(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseQuantity(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseQuantityInvalid(t *testing.T) {
+	for _, in := range []string{"", "to taste", "a pinch", "1/0", "3-2", "1 2 3", "x/2"} {
+		if got, err := ParseQuantity(in); err == nil {
+			t.Errorf("ParseQuantity(%q) = %+v, want an error", in, got)
+		}
+	}
+}
+
+func TestSplitQuantity(t *testing.T) {
+	tests := []struct {
+		in, quantity, rest string
+	}{
+		{"2 cups flour", "2", "cups flour"},
+		{"1 1/2 tsp salt", "1 1/2", "tsp salt"},
+		{"2-3 cloves garlic", "2-3", "cloves garlic"},
+		{"½ cup milk", "½", "cup milk"},
+		{"salt to taste", "", "salt to taste"},
+	}
+
+	for _, tt := range tests {
+		quantity, rest := SplitQuantity(tt.in)
+		if quantity != tt.quantity || rest != tt.rest {
+			t.Errorf("SplitQuantity(%q) = %q, %q, want %q, %q", tt.in, quantity, rest, tt.quantity, tt.rest)
+		}
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := []struct {
+		amount float64
+		unit   string
+		want   string
+	}{
+		{2, "cup", "2"},
+		{0.5, "cup", "1/2"},
+		{1.5, "cup", "1 1/2"},
+		{1.0 / 3, "cup", "1/3"},
+		{0.7, "tsp", "2/3"},
+		{1.97, "tsp", "2"},
+		{0.01, "tsp", "1/8"},
+		{0.001, "", "1/8"},
+		{0, "tsp", "0"},
+		{3, "", "3"},
+		{0.005, "g", "0.01"},
+		{0.333, "g", "0.33"},
+		{12.34, "ml", "12.3"},
+		{236.588, "ml", "237"},
+		{0, "g", "0"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatAmount(tt.amount, tt.unit); got != tt.want {
+			t.Errorf("FormatAmount(%v, %q) = %q, want %q", tt.amount, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestQuantityFormat(t *testing.T) {
+	tests := []struct {
+		q    Quantity
+		unit string
+		want string
+	}{
+		{Quantity{2, 2}, "cup", "2"},
+		{Quantity{2, 3}, "cup", "2-3"},
+		{Quantity{0.5, 0.75}, "cup", "1/2-3/4"},
+		{Quantity{1.5, 1.5 + 1e-12}, "g", "1.5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.q.Format(tt.unit); got != tt.want {
+			t.Errorf("%+v.Format(%q) = %q, want %q", tt.q, tt.unit, got, tt.want)
+		}
+	}
+}
+
+func TestScaleText(t *testing.T) {
+	tests := []struct {
+		quantity, unit string
+		factor         float64
+		wantQuantity   string
+		wantUnit       string
+		wantOK         bool
+	}{
+		{"1", "cup", 2, "2", "cups", true},
+		{"8", "tbsp", 2, "1", "cup", true},
+		{"1/2", "cup", 0.25, "2", "tbsp", true},
+		{"1/4", "tsp", 0.5, "1/8", "tsp", true},
+		{"3", "cloves", 1.5, "4 1/2", "cloves", true},
+		{"2", "cloves", 0.5, "1", "clove", true},
+		{"12-16", "tbsp", 1, "3/4-1", "cup", true},
+		{"1", "g", 0.001, "0.01", "g", true},
+		{"500", "g", 3, "1.5", "kg", true},
+		{"2", "", 1.5, "3", "", true},
+		{"to taste", "", 2, "to taste", "", false},
+	}
+
+	for _, tt := range tests {
+		quantity, unit, ok := ScaleText(tt.quantity, tt.unit, tt.factor)
+		if quantity != tt.wantQuantity || unit != tt.wantUnit || ok != tt.wantOK {
+			t.Errorf("ScaleText(%q, %q, %v) = %q, %q, %v, want %q, %q, %v",
+				tt.quantity, tt.unit, tt.factor, quantity, unit, ok, tt.wantQuantity, tt.wantUnit, tt.wantOK)
+		}
+	}
+}
+
+func TestScaleLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		factor float64
+		want   string
+	}{
+		{"- 2 cups flour, sifted", 2, "- 4 cups flour, sifted"},
+		{"* 8 Tablespoons butter", 2, "* 1 cup butter"},
+		{"1 clove garlic", 3, "3 cloves garlic"},
+		{"- 2 large eggs", 0.5, "- 1 large eggs"},
+		{"- 2-3 tbsp olive oil", 2, "- 1/4-3/8 cup olive oil"},
+		{"- 500 g pasta", 3, "- 1.5 kg pasta"},
+		{"- Salt to taste", 2, "- Salt to taste"},
+	}
+
+	for _, tt := range tests {
+		if got := ScaleLine(tt.line, tt.factor); got != tt.want {
+			t.Errorf("ScaleLine(%q, %v) = %q, want %q", tt.line, tt.factor, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package units
+
+import "strings"
+
+type Dimension int
+
+const (
+	DimensionNone Dimension = iota
+	DimensionVolume
+	DimensionWeight
+)
+
+type System int
+
+const (
+	SystemNone System = iota
+	SystemUS
+	SystemMetric
+)
+
+// Unit describes a canonical unit and its size relative to the base unit of
+// its family (tsp for US volume, oz for US weight, ml and g for metric).
+type Unit struct {
+	Name      string
+	Dimension Dimension
+	System    System
+	Base      float64
+}
+
+var knownUnits = map[string]Unit{
+	"tsp":    {Name: "tsp", Dimension: DimensionVolume, System: SystemUS, Base: 1},
+	"tbsp":   {Name: "tbsp", Dimension: DimensionVolume, System: SystemUS, Base: 3},
+	"fl oz":  {Name: "fl oz", Dimension: DimensionVolume, System: SystemUS, Base: 6},
+	"cup":    {Name: "cup", Dimension: DimensionVolume, System: SystemUS, Base: 48},
+	"pint":   {Name: "pint", Dimension: DimensionVolume, System: SystemUS, Base: 96},
+	"quart":  {Name: "quart", Dimension: DimensionVolume, System: SystemUS, Base: 192},
+	"gallon": {Name: "gallon", Dimension: DimensionVolume, System: SystemUS, Base: 768},
+	"oz":     {Name: "oz", Dimension: DimensionWeight, System: SystemUS, Base: 1},
+	"lb":     {Name: "lb", Dimension: DimensionWeight, System: SystemUS, Base: 16},
+	"ml":     {Name: "ml", Dimension: DimensionVolume, System: SystemMetric, Base: 1},
+	"l":      {Name: "l", Dimension: DimensionVolume, System: SystemMetric, Base: 1000},
+	"mg":     {Name: "mg", Dimension: DimensionWeight, System: SystemMetric, Base: 0.001},
+	"g":      {Name: "g", Dimension: DimensionWeight, System: SystemMetric, Base: 1},
+	"kg":     {Name: "kg", Dimension: DimensionWeight, System: SystemMetric, Base: 1000},
+}
+
+// promotionLadders lists, largest first, the units a scaled amount may be
+// promoted or demoted to within a family, with the smallest amount of each
+// unit that still reads naturally in a recipe.
+var promotionLadders = map[Dimension]map[System][]struct {
+	unit    string
+	minimum float64
+}{
+	DimensionVolume: {
+		SystemUS:     {{"cup", 0.25}, {"tbsp", 1}, {"tsp", 0}},
+		SystemMetric: {{"l", 1}, {"ml", 0}},
+	},
+	DimensionWeight: {
+		SystemUS:     {{"lb", 1}, {"oz", 0}},
+		SystemMetric: {{"kg", 1}, {"g", 0}},
+	},
+}
+
+var unitAliases = map[string]string{
+	"cup": "cup", "cups": "cup", "c": "cup",
+	"tablespoon": "tbsp", "tablespoons": "tbsp", "tbsp": "tbsp", "tbsps": "tbsp", "tbs": "tbsp", "tbl": "tbsp",
+	"teaspoon": "tsp", "teaspoons": "tsp", "tsp": "tsp", "tsps": "tsp",
+	"ounce": "oz", "ounces": "oz", "oz": "oz",
+	"fluid ounce": "fl oz", "fluid ounces": "fl oz", "fl oz": "fl oz", "fl. oz": "fl oz",
+	"pound": "lb", "pounds": "lb", "lb": "lb", "lbs": "lb",
+	"gram": "g", "grams": "g", "g": "g", "gr": "g",
+	"kilogram": "kg", "kilograms": "kg", "kg": "kg", "kgs": "kg",
+	"milligram": "mg", "milligrams": "mg", "mg": "mg",
+	"milliliter": "ml", "milliliters": "ml", "millilitre": "ml", "millilitres": "ml", "ml": "ml",
+	"liter": "l", "liters": "l", "litre": "l", "litres": "l", "l": "l",
+	"pint": "pint", "pints": "pint", "pt": "pint",
+	"quart": "quart", "quarts": "quart", "qt": "quart",
+	"gallon": "gallon", "gallons": "gallon", "gal": "gallon",
+	"pinch": "pinch", "pinches": "pinch",
+	"dash": "dash", "dashes": "dash",
+	"clove": "clove", "cloves": "clove",
+	"can": "can", "cans": "can",
+	"slice": "slice", "slices": "slice",
+	"stick": "stick", "sticks": "stick",
+	"package": "package", "packages": "package", "pkg": "package",
+	"bunch": "bunch", "bunches": "bunch",
+	"sprig": "sprig", "sprigs": "sprig",
+	"piece": "piece", "pieces": "piece",
+	"handful": "handful", "handfuls": "handful",
+}
+
+// Canonical maps a unit as written ("Tablespoons", "T") to the short form
+// stored in recipe_ingredients, reporting whether the unit is recognised.
+func Canonical(unit string) (string, bool) {
+	unit = strings.TrimSuffix(strings.TrimSpace(unit), ".")
+	switch unit {
+	case "T", "Tbsp", "TBSP":
+		return "tbsp", true
+	case "t":
+		return "tsp", true
+	}
+	canonical, ok := unitAliases[strings.ToLower(unit)]
+	return canonical, ok
+}
+
+// SplitUnit separates a leading unit from the rest of an ingredient line,
+// returning the canonical unit name.
+func SplitUnit(s string) (string, string, bool) {
+	fields := strings.Fields(s)
+	if len(fields) < 2 {
+		return "", s, false
+	}
+
+	// Two-word units like "fl oz" win over their one-word prefixes.
+	if len(fields) >= 3 {
+		if unit, ok := Canonical(fields[0] + " " + fields[1]); ok {
+			return unit, strings.Join(fields[2:], " "), true
+		}
+	}
+	if unit, ok := Canonical(fields[0]); ok {
+		return unit, strings.Join(fields[1:], " "), true
+	}
+	return "", s, false
+}
+
+// Lookup returns the measurement details of a unit. Units such as "clove" or
+// "can" are recognised by Canonical but have no dimension and are not found.
+func Lookup(unit string) (Unit, bool) {
+	canonical, ok := Canonical(unit)
+	if !ok {
+		return Unit{}, false
+	}
+	u, ok := knownUnits[canonical]
+	return u, ok
+}
+
+// Promote expresses amount of unit in the most readable unit of the same
+// family, e.g. 16 tbsp becomes 1 cup and 0.125 cup becomes 2 tbsp. Units
+// outside the ladder, such as quarts or fluid ounces, are left as written.
+func Promote(amount float64, unit string) (float64, string) {
+	u, ok := Lookup(unit)
+	if !ok {
+		return amount, unit
+	}
+
+	ladder := promotionLadders[u.Dimension][u.System]
+	onLadder := false
+	for _, step := range ladder {
+		if step.unit == u.Name {
+			onLadder = true
+		}
+	}
+	if !onLadder {
+		return amount, u.Name
+	}
+
+	base := amount * u.Base
+	for _, step := range ladder {
+		target := knownUnits[step.unit]
+		// Allow for rounding error from converting between systems.
+		if base/target.Base >= step.minimum-1e-9 {
+			return base / target.Base, target.Name
+		}
+	}
+	return amount, u.Name
+}
@@ -0,0 +1,83 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPromote(t *testing.T) {
+	tests := []struct {
+		amount     float64
+		unit       string
+		wantAmount float64
+		wantUnit   string
+	}{
+		{16, "tbsp", 1, "cup"},
+		{4, "tbsp", 0.25, "cup"},
+		{3, "tbsp", 3, "tbsp"},
+		{0.125, "cup", 2, "tbsp"},
+		{6, "tsp", 2, "tbsp"},
+		{0.5, "tsp", 0.5, "tsp"},
+		{32, "oz", 2, "lb"},
+		{0.5, "lb", 8, "oz"},
+		{1500, "ml", 1.5, "l"},
+		{0.25, "kg", 250, "g"},
+		{2, "quart", 2, "quart"},
+		{3, "cloves", 3, "cloves"},
+	}
+
+	for _, tt := range tests {
+		amount, unit := Promote(tt.amount, tt.unit)
+		if math.Abs(amount-tt.wantAmount) > 1e-9 || unit != tt.wantUnit {
+			t.Errorf("Promote(%v, %q) = %v, %q, want %v, %q", tt.amount, tt.unit, amount, unit, tt.wantAmount, tt.wantUnit)
+		}
+	}
+}
+
+func TestSplitUnit(t *testing.T) {
+	tests := []struct {
+		in, unit, rest string
+		ok             bool
+	}{
+		{"cups flour", "cup", "flour", true},
+		{"fl oz cream", "fl oz", "cream", true},
+		{"oz cheese", "oz", "cheese", true},
+		{"cloves garlic, minced", "clove", "garlic, minced", true},
+		{"large eggs", "", "large eggs", false},
+		{"cup", "", "cup", false},
+	}
+
+	for _, tt := range tests {
+		unit, rest, ok := SplitUnit(tt.in)
+		if unit != tt.unit || rest != tt.rest || ok != tt.ok {
+			t.Errorf("SplitUnit(%q) = %q, %q, %v, want %q, %q, %v", tt.in, unit, rest, ok, tt.unit, tt.rest, tt.ok)
+		}
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		unit   string
+		want   string
+		wantOK bool
+	}{
+		{"Tablespoons", "tbsp", true},
+		{"T", "tbsp", true},
+		{"t", "tsp", true},
+		{"tsp.", "tsp", true},
+		{"Cups", "cup", true},
+		{"fl. oz", "fl oz", true},
+		{"Grams", "g", true},
+		{"litres", "l", true},
+		{"cloves", "clove", true},
+		{"handful", "handful", true},
+		{"large", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := Canonical(tt.unit)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("Canonical(%q) = %q, %v, want %q, %v", tt.unit, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
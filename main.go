@@ -1,21 +1,22 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"os"
 
+	"recipe-ai/internal/auth"
 	"recipe-ai/internal/config"
 	"recipe-ai/internal/database"
 	"recipe-ai/internal/handlers"
+	"recipe-ai/internal/llm"
 	"recipe-ai/internal/middleware"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
-	if os.Getenv("ANTHROPIC_API_KEY") == "" {
-		log.Fatal("ANTHROPIC_API_KEY environment variable is required")
-	}
 	if os.Getenv("DATABASE_URL") == "" {
 		log.Fatal("DATABASE_URL environment variable is required")
 	}
@@ -24,6 +25,20 @@ func main() {
 
 	middleware.InitializeLogger(cfg.Environment)
 
+	if cfg.SecretKey == "" {
+		if cfg.Production() {
+			log.Fatal("SECRET_KEY environment variable is required in production")
+		}
+		// Sessions will not survive a restart, which is fine for development.
+		cfg.SecretKey = randomSecret()
+		log.Println("SECRET_KEY not set, using a random key for this run")
+	}
+
+	provider, err := llm.New(cfg)
+	if err != nil {
+		log.Fatal("Failed to configure LLM provider:", err)
+	}
+
 	db, err := database.Initialize(cfg.DatabaseURL, cfg.Environment)
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
@@ -39,6 +54,8 @@ func main() {
 		log.Fatal("Failed to run migrations:", err)
 	}
 
+	database.StartTrashPurge(db, cfg.TrashRetention)
+
 	if cfg.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -54,25 +71,144 @@ func main() {
 	router.Static("/static", "./app/static")
 	router.LoadHTMLGlob("app/templates/*")
 
-	h := handlers.New(db, cfg)
+	sessions := auth.NewSessionManager(cfg.SecretKey, cfg.SessionTTL, cfg.Production())
+	var oidc *auth.OIDCProvider
+	if cfg.OIDCIssuerURL != "" {
+		if cfg.OIDCClientID == "" {
+			log.Fatal("OIDC_CLIENT_ID is required when OIDC_ISSUER_URL is set")
+		}
+		oidc = auth.NewOIDCProvider(cfg.OIDCIssuerURL, cfg.OIDCClientID, cfg.OIDCClientSecret, cfg.OIDCRedirectURL, cfg.SecretKey, cfg.Production())
+	}
+
+	h := handlers.New(db, cfg, provider, sessions, oidc)
 	v := middleware.NewValidationMiddleware()
+	authn := auth.NewAuthenticator(db, sessions)
+	requireAuth := middleware.RequireAuth(authn)
+	canRead := middleware.RequirePermission(auth.PermRecipesRead)
+	canWrite := middleware.RequirePermission(auth.PermRecipesWrite)
+	canGenerate := middleware.RequirePermission(auth.PermGenerate)
 
 	router.GET("/health", h.Health)
 	router.GET("/ready", h.Ready)
 	router.GET("/metrics", h.Metrics)
 	router.GET("/", h.Index)
-	router.POST("/generate_recipe", middleware.GenerateRateLimitMiddleware(), h.GenerateRecipe)
-	router.POST("/save_recipe", middleware.APIRateLimitMiddleware(), h.SaveRecipe)
+	router.GET("/login", h.LoginPage)
+	router.GET("/r/:token", middleware.APIRateLimitMiddleware(), h.SharedRecipe)
+	generateLimit := middleware.GenerateRateLimitMiddleware()
+
+	router.POST("/generate_recipe", requireAuth, canGenerate, generateLimit, h.GenerateRecipe)
+	router.GET("/generate_recipe/stream", requireAuth, canGenerate, generateLimit, h.GenerateRecipeStream)
+	router.POST("/generate_recipe/stream", requireAuth, canGenerate, generateLimit, h.GenerateRecipeStream)
+	router.POST("/save_recipe", requireAuth, canWrite, middleware.APIRateLimitMiddleware(), h.SaveRecipe)
 	router.POST("/export_recipe/:format", middleware.APIRateLimitMiddleware(), h.ExportRecipe)
 	router.POST("/validate_ingredients", middleware.APIRateLimitMiddleware(), h.ValidateIngredients)
 
-	api := router.Group("/api", middleware.APIRateLimitMiddleware())
+	authRoutes := router.Group("/auth", middleware.AuthRateLimitMiddleware())
+	{
+		authRoutes.POST("/register", h.Register)
+		authRoutes.POST("/login", h.Login)
+		authRoutes.POST("/logout", h.Logout)
+		authRoutes.GET("/me", requireAuth, h.CurrentUser)
+		authRoutes.GET("/oidc/login", h.OIDCLogin)
+		authRoutes.GET("/oidc/callback", h.OIDCCallback)
+	}
+
+	// Authenticate before rate limiting so API tokens get their own buckets.
+	api := router.Group("/api", requireAuth, middleware.APIRateLimitMiddleware())
 	{
-		api.GET("/recipes", v.ValidatePagination(), h.GetRecipes)
-		api.GET("/recipes/:id", v.ValidateIDParam(), h.GetRecipe)
-		api.PUT("/recipes/:id", v.ValidateIDParam(), h.UpdateRecipe)
-		api.DELETE("/recipes/:id", v.ValidateIDParam(), h.DeleteRecipe)
-		api.PUT("/recipes/:id/rating", v.ValidateIDParam(), h.UpdateRecipeRating)
+		api.GET("/recipes", canRead, v.ValidatePagination(), h.GetRecipes)
+		api.GET("/recipes/match", canRead, h.MatchRecipes)
+		api.GET("/recipes/:id", canRead, v.ValidateIDParam(), h.GetRecipe)
+		api.PUT("/recipes/:id", canWrite, v.ValidateIDParam(), h.UpdateRecipe)
+		api.DELETE("/recipes/:id", canWrite, v.ValidateIDParam(), h.DeleteRecipe)
+		api.PUT("/recipes/:id/rating", canWrite, v.ValidateIDParam(), h.UpdateRecipeRating)
+		api.GET("/recipes/:id/scaled", canRead, v.ValidateIDParam(), h.GetScaledRecipe)
+		api.POST("/recipes/:id/share", canWrite, v.ValidateIDParam(), h.CreateShare)
+		api.GET("/recipes/:id/shares", canRead, v.ValidateIDParam(), h.ListShares)
+		api.GET("/recipes/:id/revisions", canRead, v.ValidateIDParam(), h.GetRecipeRevisions)
+		api.GET("/recipes/:id/revisions/diff", canRead, v.ValidateIDParam(), h.DiffRecipeRevisions)
+		api.GET("/recipes/:id/revisions/:rev", canRead, v.ValidateIDParam(), h.GetRecipeRevision)
+		api.POST("/recipes/:id/revisions/:rev/restore", canWrite, v.ValidateIDParam(), h.RestoreRecipeRevision)
+		api.POST("/recipes/:id/tags", canWrite, v.ValidateIDParam(), h.AddRecipeTags)
+		api.DELETE("/recipes/:id/tags/:tag", canWrite, v.ValidateIDParam(), h.RemoveRecipeTag)
+		api.DELETE("/shares/:id", canWrite, v.ValidateIDParam(), h.RevokeShare)
+		api.GET("/tags", canRead, h.GetTags)
+		api.GET("/tags/autocomplete", canRead, h.AutocompleteTags)
+
+		trash := api.Group("/trash")
+		{
+			trash.GET("", canRead, v.ValidatePagination(), h.GetTrash)
+			trash.DELETE("", canWrite, h.EmptyTrash)
+			trash.POST("/:id/restore", canWrite, v.ValidateIDParam(), h.RestoreRecipe)
+			trash.DELETE("/:id", canWrite, v.ValidateIDParam(), h.PurgeRecipe)
+		}
+
+		collections := api.Group("/collections")
+		{
+			collections.GET("", canRead, h.ListCollections)
+			collections.POST("", canWrite, h.CreateCollection)
+			collections.GET("/:id", canRead, v.ValidateIDParam(), h.GetCollection)
+			collections.PUT("/:id", canWrite, v.ValidateIDParam(), h.UpdateCollection)
+			collections.DELETE("/:id", canWrite, v.ValidateIDParam(), h.DeleteCollection)
+			collections.POST("/:id/recipes", canWrite, v.ValidateIDParam(), h.AddCollectionRecipe)
+			collections.PUT("/:id/recipes", canWrite, v.ValidateIDParam(), h.ReorderCollection)
+			collections.DELETE("/:id/recipes/:recipe_id", canWrite, v.ValidateIDParam(), h.RemoveCollectionRecipe)
+			collections.GET("/:id/export/:format", canRead, v.ValidateIDParam(), h.ExportCollection)
+		}
+
+		mealPlan := api.Group("/meal-plan")
+		{
+			mealPlan.GET("", canRead, h.GetMealPlan)
+			mealPlan.POST("", canWrite, h.CreateMealPlanEntry)
+			mealPlan.GET("/:id", canRead, v.ValidateIDParam(), h.GetMealPlanEntry)
+			mealPlan.PUT("/:id", canWrite, v.ValidateIDParam(), h.UpdateMealPlanEntry)
+			mealPlan.DELETE("/:id", canWrite, v.ValidateIDParam(), h.DeleteMealPlanEntry)
+		}
+
+		pantry := api.Group("/pantry")
+		{
+			pantry.GET("", canRead, h.GetPantry)
+			pantry.POST("", canWrite, h.CreatePantryItem)
+			pantry.GET("/:id", canRead, v.ValidateIDParam(), h.GetPantryItem)
+			pantry.PUT("/:id", canWrite, v.ValidateIDParam(), h.UpdatePantryItem)
+			pantry.DELETE("/:id", canWrite, v.ValidateIDParam(), h.DeletePantryItem)
+		}
+
+		conversations := api.Group("/conversations")
+		{
+			conversations.GET("", canRead, h.ListConversations)
+			conversations.POST("", canWrite, h.StartConversation)
+			conversations.GET("/:id", canRead, v.ValidateIDParam(), h.GetConversation)
+			conversations.DELETE("/:id", canWrite, v.ValidateIDParam(), h.DeleteConversation)
+			conversations.POST("/:id/messages", canGenerate, generateLimit, v.ValidateIDParam(), h.RefineRecipe)
+			conversations.POST("/:id/messages/:position/save", canWrite, v.ValidateIDParam(), h.SaveConversationTurn)
+		}
+
+		shoppingLists := api.Group("/shopping-lists")
+		{
+			shoppingLists.GET("", canRead, h.ListShoppingLists)
+			shoppingLists.POST("", canWrite, h.CreateShoppingList)
+			shoppingLists.GET("/:id", canRead, v.ValidateIDParam(), h.GetShoppingList)
+			shoppingLists.DELETE("/:id", canWrite, v.ValidateIDParam(), h.DeleteShoppingList)
+			shoppingLists.PUT("/:id/items/:item_id", canWrite, v.ValidateIDParam(), h.UpdateShoppingListItem)
+			shoppingLists.GET("/:id/export/:format", canRead, v.ValidateIDParam(), h.ExportShoppingList)
+		}
+
+		tokens := api.Group("/tokens", middleware.RequireSession())
+		{
+			tokens.GET("", h.ListTokens)
+			tokens.POST("", h.CreateToken)
+			tokens.DELETE("/:id", v.ValidateIDParam(), h.RevokeToken)
+		}
+
+		admin := api.Group("/admin", middleware.RequireRole(auth.RoleAdmin))
+		{
+			admin.GET("/recipes", middleware.RequirePermission(auth.PermAdminRecipes), v.ValidatePagination(), h.AdminGetRecipes)
+			admin.DELETE("/recipes/:id", middleware.RequirePermission(auth.PermAdminRecipes), v.ValidateIDParam(), h.AdminDeleteRecipe)
+			admin.GET("/users", middleware.RequirePermission(auth.PermAdminUsers), h.AdminGetUsers)
+			admin.PUT("/users/:id/role", middleware.RequirePermission(auth.PermAdminUsers), v.ValidateIDParam(), h.AdminUpdateUserRole)
+			admin.GET("/usage", middleware.RequirePermission(auth.PermAdminUsage), h.AdminGetUsage)
+		}
 	}
 
 	log.Printf("Server starting on port %s", cfg.Port)
@@ -80,3 +216,11 @@ func main() {
 		log.Fatal("Failed to start server:", err)
 	}
 }
+
+func randomSecret() string {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		log.Fatal("Failed to generate secret key:", err)
+	}
+	return hex.EncodeToString(buf)
+}
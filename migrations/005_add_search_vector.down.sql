@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_recipes_search_vector;
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+DROP FUNCTION IF EXISTS recipes_search_vector_update();
+ALTER TABLE recipes DROP COLUMN IF EXISTS search_vector;
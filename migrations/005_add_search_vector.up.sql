@@ -0,0 +1,24 @@
+-- Weighted full-text search vector maintained by trigger: title > ingredients > content
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION recipes_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', coalesce(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(NEW.ingredients_used, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(NEW.recipe_content, '')), 'C');
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS recipes_search_vector_trigger ON recipes;
+CREATE TRIGGER recipes_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF title, ingredients_used, recipe_content ON recipes
+    FOR EACH ROW EXECUTE FUNCTION recipes_search_vector_update();
+
+UPDATE recipes SET search_vector =
+    setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(ingredients_used, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(recipe_content, '')), 'C');
+
+CREATE INDEX IF NOT EXISTS idx_recipes_search_vector ON recipes USING GIN (search_vector);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_recipes_ingredients_used_trgm;
+DROP INDEX IF EXISTS idx_recipes_title_trgm;
@@ -0,0 +1,5 @@
+-- Trigram indexes for typo-tolerant fallback search on title and ingredients
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_title_trgm ON recipes USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_recipes_ingredients_used_trgm ON recipes USING GIN (ingredients_used gin_trgm_ops);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_recipes_user_id;
+ALTER TABLE recipes DROP COLUMN IF EXISTS user_id;
+DROP TABLE IF EXISTS users;
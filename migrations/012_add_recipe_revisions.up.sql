@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS recipe_revisions (
+    id SERIAL PRIMARY KEY,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    title VARCHAR(200) NOT NULL,
+    recipe_content TEXT NOT NULL,
+    ingredients_used TEXT NOT NULL,
+    dietary_restrictions VARCHAR(100),
+    cuisine_preference VARCHAR(100),
+    serving_size INTEGER,
+    author_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipe_revisions_recipe_revision ON recipe_revisions(recipe_id, revision);
+CREATE INDEX IF NOT EXISTS idx_recipe_revisions_author_id ON recipe_revisions(author_id);
+
+-- Existing recipes start their history with their current content. Edits made
+-- before this migration cannot be recovered.
+INSERT INTO recipe_revisions (recipe_id, revision, title, recipe_content, ingredients_used,
+    dietary_restrictions, cuisine_preference, serving_size, author_id, created_at)
+SELECT id, 1, title, recipe_content, ingredients_used,
+    dietary_restrictions, cuisine_preference, serving_size, user_id, COALESCE(updated_at, created_at)
+FROM recipes
+WHERE NOT EXISTS (SELECT 1 FROM recipe_revisions rr WHERE rr.recipe_id = recipes.id);
@@ -0,0 +1,5 @@
+-- Without the column trashed recipes would reappear, so purge them first.
+DELETE FROM recipes WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_recipes_deleted_at;
+ALTER TABLE recipes DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,3 @@
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_deleted_at ON recipes(deleted_at);
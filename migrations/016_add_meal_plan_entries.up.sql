@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS meal_plan_entries (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id INTEGER NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    date DATE NOT NULL,
+    slot VARCHAR(20) NOT NULL,
+    servings_override INTEGER,
+    note VARCHAR(200) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_plan_entries_user_date ON meal_plan_entries(user_id, date);
+CREATE INDEX IF NOT EXISTS idx_meal_plan_entries_recipe_id ON meal_plan_entries(recipe_id);
+
+ALTER TABLE meal_plan_entries DROP CONSTRAINT IF EXISTS chk_meal_plan_entries_slot;
+ALTER TABLE meal_plan_entries ADD CONSTRAINT chk_meal_plan_entries_slot CHECK (slot IN ('breakfast', 'lunch', 'dinner', 'snack'));
+
+ALTER TABLE meal_plan_entries DROP CONSTRAINT IF EXISTS chk_meal_plan_entries_servings_override;
+ALTER TABLE meal_plan_entries ADD CONSTRAINT chk_meal_plan_entries_servings_override CHECK (servings_override IS NULL OR servings_override BETWEEN 1 AND 100);
@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS conversations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    recipe_id INTEGER REFERENCES recipes(id) ON DELETE SET NULL,
+    ingredients_used TEXT NOT NULL,
+    dietary_restrictions VARCHAR(100) NOT NULL DEFAULT '',
+    cuisine_preference VARCHAR(100) NOT NULL DEFAULT '',
+    serving_size INTEGER NOT NULL DEFAULT 4,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversations_user_id ON conversations(user_id);
+CREATE INDEX IF NOT EXISTS idx_conversations_recipe_id ON conversations(recipe_id);
+
+CREATE TABLE IF NOT EXISTS conversation_messages (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    content TEXT NOT NULL,
+    structured TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_conversation_messages_position ON conversation_messages(conversation_id, position);
+
+ALTER TABLE conversation_messages DROP CONSTRAINT IF EXISTS chk_conversation_messages_role;
+ALTER TABLE conversation_messages ADD CONSTRAINT chk_conversation_messages_role CHECK (role IN ('user', 'assistant'));